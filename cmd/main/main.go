@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	application "cryptoObserver/internal/app/server"
+	"flag"
 	"log"
 	"net/http"
 	"os"
@@ -11,6 +12,22 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	storage := flag.String("storage", "", "Хранилище: postgres, sqlite или memory (переопределяет DB_DRIVER)")
+	skipSchemaCheck := flag.Bool("skip-schema-check", false, "Запускать сервер, даже если схема БД отстает от миграций")
+	flag.Parse()
+	if *storage != "" {
+		_ = os.Setenv("DB_DRIVER", *storage)
+	}
+	if *skipSchemaCheck {
+		_ = os.Setenv("DB_SKIP_SCHEMA_CHECK", "true")
+	}
 	config := application.LoadConfig()
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
@@ -34,12 +51,12 @@ func main() {
 		case <-ctx.Done():
 			if err = app.Server.Shutdown(ctx); err != nil {
 				log.Printf("Ошибка при остановке сервера: %v", err)
-				if err = app.Server.Close(); err != nil {
+				if err = app.Close(); err != nil {
 					log.Printf("Ошибка при закрытии сервера: %v", err)
 				}
 				return
 			} else {
-				if err = app.Server.Close(); err != nil {
+				if err = app.Close(); err != nil {
 					log.Printf("Ошибка при закрытии сервера: %v", err)
 				}
 				log.Println("Сервер успешно остановлен")
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"cryptoObserver/internal/app/migrations"
+	application "cryptoObserver/internal/app/server"
+	"flag"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+)
+
+// migrationsRoot - каталог исходников миграций относительно корня репозитория
+const migrationsRoot = "internal/app/migrations"
+
+const migrateUsage = `Использование: cryptoObserver migrate [флаги] <команда>
+
+Команды:
+  up           применить все новые миграции
+  down         откатить последнюю миграцию
+  status       показать состояние миграций
+  redo         откатить и заново применить последнюю миграцию
+  create NAME  создать файл новой миграции
+
+Флаги:
+`
+
+// runMigrate выполняет подкоманду migrate
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	storage := flags.String("storage", "", "Хранилище: postgres или sqlite (переопределяет DB_DRIVER)")
+	timescale := flags.Bool("timescale", false, "Работать с набором миграций TimescaleDB")
+	dir := flags.String("dir", "", "Каталог для create, по умолчанию каталог набора в "+migrationsRoot)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), migrateUsage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if *storage != "" {
+		_ = os.Setenv("DB_DRIVER", *storage)
+	}
+
+	command := flags.Arg(0)
+	switch command {
+	case "create":
+		// Для create подключение к БД не нужно
+		name := flags.Arg(1)
+		if name == "" {
+			return fmt.Errorf("migrate create: migration name is required")
+		}
+		if *dir == "" {
+			set, err := migrationSet(os.Getenv("DB_DRIVER"), *timescale)
+			if err != nil {
+				return err
+			}
+			*dir = filepath.Join(migrationsRoot, set.Dir())
+		}
+		return migrations.Create(*dir, name)
+	case "up", "down", "status", "redo":
+	case "":
+		flags.Usage()
+		return fmt.Errorf("migrate: command is required")
+	default:
+		return fmt.Errorf("migrate: unknown command %q", command)
+	}
+
+	config := application.LoadConfig()
+	logger := logrus.New()
+	db, set, err := application.OpenDatabase(context.Background(), config, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if *timescale {
+		if set.Name != migrations.Postgres.Name {
+			return fmt.Errorf("migrate: timescale migrations require DB_DRIVER=postgres")
+		}
+		set = migrations.Timescale
+	}
+
+	switch command {
+	case "up":
+		return set.Migrate(db, logger)
+	case "down":
+		return set.Down(db)
+	case "redo":
+		return set.Redo(db)
+	default:
+		return set.Status(db)
+	}
+}
+
+// migrationSet возвращает набор миграций для драйвера без подключения к БД
+func migrationSet(driver string, timescale bool) (migrations.Set, error) {
+	switch driver {
+	case "", application.DriverPostgres:
+		if timescale {
+			return migrations.Timescale, nil
+		}
+		return migrations.Postgres, nil
+	case application.DriverSQLite:
+		if !timescale {
+			return migrations.SQLite, nil
+		}
+	}
+	return migrations.Set{}, fmt.Errorf("migrate: no migrations for driver %q", driver)
+}
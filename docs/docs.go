@@ -16,6 +16,125 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/analytics/correlation": {
+            "get": {
+                "description": "Матрица корреляций Пирсона доходностей валют ids за период. Ряды выравниваются на общую сетку с шагом interval:\nцена в узле - последняя точка не позже него. Шаг стоит выбирать не меньше периода опроса цен,\nиначе повторяющиеся цены дают нулевые доходности. По умолчанию - последние 30 дней с шагом 1h.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Корреляция валют",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID валют через запятую, от 2 до 20",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, unix timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, unix timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг сетки: 5m, 1h, 1d и т.п.",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Correlation matrix",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.Correlation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/convert": {
+            "get": {
+                "description": "Пересчет amount валюты from в валюту to через кросс-курс по USD-ценам, ближайшим к моменту at.\nВ ответе - использованные точки обеих валют и разрыв между ними. Если разрыв больше CONVERT_MAX_SKEW,\nпересчет отклоняется: цены с разных моментов дают неверный курс.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Пересчет между валютами",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID исходной валюты",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID целевой валюты",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Количество исходной валюты. По умолчанию 1",
+                        "name": "amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Момент пересчета, unix timestamp. По умолчанию - текущий",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Conversion result",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.conversionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - No price found for currency",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity - Prices are too far apart in time",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/currency/add": {
             "post": {
                 "description": "Добавление валюты в список валют для отслеживания.",
@@ -85,13 +204,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Price of the currency",
+                        "description": "Price of the currency as a decimal string",
                         "schema": {
-                            "type": "number"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Currency ID is required",
+                        "description": "Bad Request - Timestamp is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - No price found for the given currency and timestamp",
                         "schema": {
                             "type": "string"
                         }
@@ -136,6 +261,1115 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/currency/snapshots": {
+            "get": {
+                "description": "История рыночных данных валюты: цена, капитализация, объем торгов, максимум и минимум за 24ч, изменение цены, предложение и ATH.\nПо умолчанию возвращаются данные за последние сутки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Рыночные данные валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID валюты",
+                        "name": "currencyID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, unix timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, unix timestamp",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Market snapshots ordered by timestamp",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.MarketSnapshot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid period",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{id}/indicators": {
+            "get": {
+                "description": "Индикатор по ценам закрытия интервалов interval: sma, ema, rsi, bollinger или macd.\nЦена закрытия интервала - последняя точка не позже его конца. История для разогрева индикатора\nподгружается до from, поэтому значения есть с начала периода. По умолчанию - последние 7 дней с шагом 1h.\nЛинии: value для sma, ema и rsi; middle, upper, lower для bollinger; macd, signal, histogram для macd.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Технический индикатор",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID валюты",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Индикатор: sma, ema, rsi, bollinger, macd",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Окно индикатора. По умолчанию 14 для rsi, 20 для остальных",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг свечей: 5m, 1h, 1d и т.п.",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, unix timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, unix timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Ширина полос Боллинджера в стандартных отклонениях. По умолчанию 2",
+                        "name": "k",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Быстрая EMA для macd. По умолчанию 12",
+                        "name": "fast",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Медленная EMA для macd. По умолчанию 26",
+                        "name": "slow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сигнальная линия macd. По умолчанию 9",
+                        "name": "signal",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Indicator values",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.indicatorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{id}/sources": {
+            "get": {
+                "description": "Цены от каждого провайдера в режиме консенсуса (в PRICE_SOURCES больше одного провайдера):\nитоговая цена, отклонение провайдера от нее в процентах и признак превышения порога.\nПо умолчанию возвращаются данные за последние сутки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Цены провайдеров валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID валюты",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, unix timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, unix timestamp",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Source prices ordered by timestamp",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SourcePrice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid period",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{id}/stats": {
+            "get": {
+                "description": "Сводка по точкам цены за последнее окно window: минимум, максимум, среднее, медиана,\nстандартное отклонение, изменение последней цены к первой, время первой и последней точки и их число.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Статистика цены валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID валюты",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Окно: 24h, 7d или 30d. По умолчанию 24h",
+                        "name": "window",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price statistics",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.Summary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid window",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - No prices in window",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Счетчики кеша последних цен: попадания, промахи, доля попаданий, число валют в кеше.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service"
+                ],
+                "summary": "Метрики сервиса",
+                "responses": {
+                    "200": {
+                        "description": "Metrics",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.metricsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios": {
+            "post": {
+                "description": "Создание пустого портфеля. Позиции добавляются через /portfolios/{id}/positions.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Создание портфеля",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название портфеля",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/model.Portfolio"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Portfolio name is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}": {
+            "get": {
+                "description": "Портфель со всеми позициями.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Портфель",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Portfolio with positions",
+                        "schema": {
+                            "$ref": "#/definitions/model.Portfolio"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid portfolio ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Portfolio not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/performance": {
+            "get": {
+                "description": "Стоимость портфеля на сетке с шагом resolution за период и показатели: доходность, максимальная просадка,\nгодовая волатильность и коэффициент Шарпа. Доходность взвешена по времени: покупки внутри периода не считаются ростом.\nПо умолчанию - последние 30 дней с шагом 1h.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Динамика стоимости портфеля",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, unix timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, unix timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг сетки: 5m, 1h, 1d и т.п.",
+                        "name": "resolution",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Годовая безрисковая ставка для Шарпа, доля. По умолчанию 0",
+                        "name": "riskFree",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Portfolio value series and metrics",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.Performance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Portfolio not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/positions": {
+            "post": {
+                "description": "Добавление покупки валюты в портфель. Валюта автоматически ставится на отслеживание.\ncostBasis - полная стоимость покупки, а не цена за единицу.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Добавление позиции в портфель",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID валюты",
+                        "name": "coinID",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Количество",
+                        "name": "quantity",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Стоимость покупки",
+                        "name": "costBasis",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Время покупки, unix timestamp. По умолчанию - текущее",
+                        "name": "acquiredAt",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Added position",
+                        "schema": {
+                            "$ref": "#/definitions/model.Position"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid position",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Portfolio not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/valuation": {
+            "get": {
+                "description": "Стоимость портфеля на момент at по истории цен: стоимость, прибыль/убыток и доля каждой позиции.\nПозиция оценивается по последней цене не позже at; позиции, купленные после at, не учитываются.\nВалюты без цены на этот момент перечислены в unpriced и не входят в итоги.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Оценка портфеля",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Момент оценки, unix timestamp. По умолчанию - текущий",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Portfolio valuation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Valuation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid at timestamp",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Portfolio not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/quarantine": {
+            "get": {
+                "description": "Входящие цены, отклоненные проверкой аномалий: цена не больше нуля (non_positive),\nскачок относительно скользящей медианы (jump) или повтор без обновления у провайдера (stale).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quarantine"
+                ],
+                "summary": "Цены в карантине",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID валюты. По умолчанию - все валюты",
+                        "name": "currencyID",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Quarantined prices ordered by timestamp",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.QuarantinedPrice"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/quarantine/{id}": {
+            "delete": {
+                "description": "Удаляет цену из карантина, не записывая ее в историю.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quarantine"
+                ],
+                "summary": "Отклонить цену из карантина",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID записи карантина",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - Price discarded",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid quarantine ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/quarantine/{id}/accept": {
+            "post": {
+                "description": "Записывает цену из карантина в историю, несмотря на проверку аномалий, и удаляет ее из карантина.\nВалюта должна отслеживаться.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quarantine"
+                ],
+                "summary": "Принять цену из карантина",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID записи карантина",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - Price accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid quarantine ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Quarantined price not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Currency is not tracked",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "analytics.Correlation": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "interval": {
+                    "description": "Шаг сетки, сек",
+                    "type": "integer"
+                },
+                "matrix": {
+                    "description": "Matrix[i][j] - корреляция IDs[i] и IDs[j], null если общих доходностей меньше трех\nили одна из валют не менялась в цене",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "samples": {
+                    "description": "Samples[i][j] - число общих доходностей, по которым посчитана корреляция",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "analytics.Metrics": {
+            "type": "object",
+            "properties": {
+                "max_drawdown": {
+                    "description": "Максимальная просадка от пика, доля",
+                    "type": "number"
+                },
+                "periods": {
+                    "description": "Число учтенных доходностей",
+                    "type": "integer"
+                },
+                "risk_free": {
+                    "description": "Годовая безрисковая ставка, использованная для Шарпа",
+                    "type": "number"
+                },
+                "sharpe": {
+                    "description": "Годовой коэффициент Шарпа, nil при нулевой волатильности",
+                    "type": "number"
+                },
+                "total_return": {
+                    "description": "Взвешенная по времени доходность за период",
+                    "type": "number"
+                },
+                "volatility": {
+                    "description": "Годовая волатильность доходностей",
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.Performance": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer"
+                },
+                "metrics": {
+                    "$ref": "#/definitions/analytics.Metrics"
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "resolution": {
+                    "description": "Шаг сетки, сек",
+                    "type": "integer"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.ValuePoint"
+                    }
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "analytics.Summary": {
+            "type": "object",
+            "properties": {
+                "change_percent": {
+                    "description": "Изменение последней цены к первой, %",
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "currency_id": {
+                    "type": "string"
+                },
+                "first": {
+                    "type": "string"
+                },
+                "first_timestamp": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "last": {
+                    "type": "string"
+                },
+                "last_timestamp": {
+                    "type": "integer"
+                },
+                "max": {
+                    "type": "string"
+                },
+                "mean": {
+                    "type": "string"
+                },
+                "median": {
+                    "type": "string"
+                },
+                "min": {
+                    "type": "string"
+                },
+                "stddev": {
+                    "description": "Выборочное стандартное отклонение",
+                    "type": "number"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
+        "analytics.ValuePoint": {
+            "type": "object",
+            "properties": {
+                "return": {
+                    "description": "Return - доходность с предыдущего узла без учета новых покупок, nil для первого узла",
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.conversionResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "at": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "from_price": {
+                    "description": "Цена from в USD и момент точки",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.PricePoint"
+                        }
+                    ]
+                },
+                "rate": {
+                    "description": "Сколько to за одну from",
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "skew": {
+                    "description": "Разрыв между точками двух валют, сек",
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "to_price": {
+                    "$ref": "#/definitions/model.PricePoint"
+                }
+            }
+        },
+        "handlers.indicatorPoint": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                }
+            }
+        },
+        "handlers.indicatorResponse": {
+            "type": "object",
+            "properties": {
+                "currency_id": {
+                    "type": "string"
+                },
+                "interval": {
+                    "description": "Шаг свечей, сек",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.indicatorPoint"
+                    }
+                }
+            }
+        },
+        "handlers.metricsResponse": {
+            "type": "object",
+            "properties": {
+                "price_cache": {
+                    "$ref": "#/definitions/pricecache.Stats"
+                }
+            }
+        },
+        "model.MarketSnapshot": {
+            "type": "object",
+            "properties": {
+                "ath": {
+                    "type": "string"
+                },
+                "circulating_supply": {
+                    "type": "string"
+                },
+                "high_24h": {
+                    "type": "string"
+                },
+                "low_24h": {
+                    "type": "string"
+                },
+                "market_cap": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "price_change_percentage_24h": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "total_volume": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Portfolio": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Position"
+                    }
+                }
+            }
+        },
+        "model.Position": {
+            "type": "object",
+            "properties": {
+                "acquired_at": {
+                    "type": "integer"
+                },
+                "coin_id": {
+                    "type": "string"
+                },
+                "cost_basis": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PositionValuation": {
+            "type": "object",
+            "properties": {
+                "acquired_at": {
+                    "type": "integer"
+                },
+                "allocation_percent": {
+                    "type": "string"
+                },
+                "coin_id": {
+                    "type": "string"
+                },
+                "cost_basis": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "pnl": {
+                    "type": "string"
+                },
+                "pnl_percent": {
+                    "type": "string"
+                },
+                "price": {
+                    "$ref": "#/definitions/model.PricePoint"
+                },
+                "quantity": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PricePoint": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.QuarantinedPrice": {
+            "type": "object",
+            "properties": {
+                "coin_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "non_positive, jump или stale",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.SourcePrice": {
+            "type": "object",
+            "properties": {
+                "coin_id": {
+                    "type": "string"
+                },
+                "consensus": {
+                    "type": "string"
+                },
+                "deviation_percent": {
+                    "type": "string"
+                },
+                "flagged": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Valuation": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "integer"
+                },
+                "pnl": {
+                    "type": "string"
+                },
+                "pnl_percent": {
+                    "type": "string"
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PositionValuation"
+                    }
+                },
+                "total_cost": {
+                    "type": "string"
+                },
+                "total_value": {
+                    "type": "string"
+                },
+                "unpriced": {
+                    "description": "Валюты без цены на момент At",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "pricecache.Stats": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "integer"
+                },
+                "hit_ratio": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
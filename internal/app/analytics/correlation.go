@@ -0,0 +1,88 @@
+package analytics
+
+import (
+	"cryptoObserver/internal/app/model"
+	"math"
+)
+
+// minCorrelationSamples - минимум общих доходностей для оценки корреляции
+const minCorrelationSamples = 3
+
+// Correlation - матрица корреляций Пирсона доходностей валют
+type Correlation struct {
+	IDs      []string `json:"ids"`
+	From     int64    `json:"from"`
+	To       int64    `json:"to"`
+	Interval int64    `json:"interval"` // Шаг сетки, сек
+	// Matrix[i][j] - корреляция IDs[i] и IDs[j], null если общих доходностей меньше трех
+	// или одна из валют не менялась в цене
+	Matrix [][]*float64 `json:"matrix"`
+	// Samples[i][j] - число общих доходностей, по которым посчитана корреляция
+	Samples [][]int `json:"samples"`
+}
+
+// Correlate выравнивает ряды ids на общую сетку grid и считает попарные корреляции
+// доходностей между соседними узлами. Пара учитывает только узлы, где доходность
+// есть у обеих валют.
+func Correlate(ids []string, series map[string][]model.PricePoint, grid []int64) Correlation {
+	correlation := Correlation{
+		IDs:     ids,
+		Matrix:  make([][]*float64, len(ids)),
+		Samples: make([][]int, len(ids)),
+	}
+	if len(grid) > 0 {
+		correlation.From, correlation.To = grid[0], grid[len(grid)-1]
+	}
+	if len(grid) > 1 {
+		correlation.Interval = grid[1] - grid[0]
+	}
+
+	returns := make([][]float64, len(ids))
+	for i, id := range ids {
+		returns[i] = gridReturns(series[id], grid)
+	}
+
+	for i := range ids {
+		correlation.Matrix[i] = make([]*float64, len(ids))
+		correlation.Samples[i] = make([]int, len(ids))
+	}
+	for i := range ids {
+		for j := i; j < len(ids); j++ {
+			var x, y []float64
+			for k := range grid {
+				if !math.IsNaN(returns[i][k]) && !math.IsNaN(returns[j][k]) {
+					x = append(x, returns[i][k])
+					y = append(y, returns[j][k])
+				}
+			}
+			correlation.Samples[i][j], correlation.Samples[j][i] = len(x), len(x)
+			if len(x) < minCorrelationSamples {
+				continue
+			}
+			if r, ok := Pearson(x, y); ok {
+				correlation.Matrix[i][j], correlation.Matrix[j][i] = &r, &r
+			}
+		}
+	}
+	return correlation
+}
+
+// gridReturns возвращает доходность в каждом узле grid относительно предыдущего,
+// NaN - если цены в одном из узлов нет
+func gridReturns(series []model.PricePoint, grid []int64) []float64 {
+	returns := make([]float64, len(grid))
+	prev := math.NaN()
+	for k, t := range grid {
+		returns[k] = math.NaN()
+		point, ok := AsOf(series, t)
+		if !ok {
+			continue
+		}
+		price := point.Price.Float64()
+		if !math.IsNaN(prev) && prev != 0 {
+			returns[k] = price/prev - 1
+		}
+		prev = price
+	}
+	return returns
+}
@@ -0,0 +1,130 @@
+package analytics
+
+import (
+	"cryptoObserver/internal/app/model"
+	"math"
+	"time"
+)
+
+const secondsPerYear = 365 * 24 * 60 * 60
+
+// ValuePoint - стоимость портфеля в узле сетки
+type ValuePoint struct {
+	Timestamp int64         `json:"timestamp"`
+	Value     model.Decimal `json:"value" swaggertype:"string"`
+	// Return - доходность с предыдущего узла без учета новых покупок, nil для первого узла
+	Return *float64 `json:"return"`
+}
+
+// Metrics - показатели доходности и риска за период
+type Metrics struct {
+	TotalReturn float64  `json:"total_return"` // Взвешенная по времени доходность за период
+	MaxDrawdown float64  `json:"max_drawdown"` // Максимальная просадка от пика, доля
+	Volatility  float64  `json:"volatility"`   // Годовая волатильность доходностей
+	Sharpe      *float64 `json:"sharpe"`       // Годовой коэффициент Шарпа, nil при нулевой волатильности
+	Periods     int      `json:"periods"`      // Число учтенных доходностей
+	RiskFree    float64  `json:"risk_free"`    // Годовая безрисковая ставка, использованная для Шарпа
+}
+
+// Performance - динамика стоимости портфеля и ее показатели
+type Performance struct {
+	PortfolioID int64        `json:"portfolio_id"`
+	From        int64        `json:"from"`
+	To          int64        `json:"to"`
+	Resolution  int64        `json:"resolution"` // Шаг сетки, сек
+	Series      []ValuePoint `json:"series"`
+	Metrics     Metrics      `json:"metrics"`
+}
+
+// PortfolioPerformance считает стоимость портфеля в узлах grid и показатели за период.
+// series - упорядоченные ряды цен по валютам, включая последнюю точку до начала сетки.
+// Позиция оценивается по последней цене не позже узла. Доходность узла считается
+// только по позициям, которые были в портфеле и имели цену в обоих соседних узлах,
+// поэтому покупки не выглядят как рост (time-weighted return).
+// Узлы, в которых ни одна позиция не оценена, пропускаются.
+func PortfolioPerformance(portfolio model.Portfolio, series map[string][]model.PricePoint, grid []int64, step time.Duration, riskFree float64) Performance {
+	performance := Performance{
+		PortfolioID: portfolio.ID,
+		Resolution:  int64(step / time.Second),
+		Series:      []ValuePoint{},
+	}
+	if len(grid) > 0 {
+		performance.From, performance.To = grid[0], grid[len(grid)-1]
+	}
+
+	var returns []float64
+	var prevTimestamp int64
+	hasPrev := false
+	for _, t := range grid {
+		value, priced := valueAt(portfolio.Positions, series, t, t)
+		if !priced {
+			continue
+		}
+		point := ValuePoint{Timestamp: t, Value: value}
+		if hasPrev {
+			// Те же позиции, что были в предыдущем узле, по ценам двух узлов
+			before, ok := valueAt(portfolio.Positions, series, prevTimestamp, prevTimestamp)
+			after, _ := valueAt(portfolio.Positions, series, prevTimestamp, t)
+			if ok && before.Sign() > 0 {
+				r := after.Float64()/before.Float64() - 1
+				point.Return = &r
+				returns = append(returns, r)
+			}
+		}
+		performance.Series = append(performance.Series, point)
+		prevTimestamp, hasPrev = t, true
+	}
+
+	periodsPerYear := float64(secondsPerYear) / step.Seconds()
+	performance.Metrics = ComputeMetrics(returns, periodsPerYear, riskFree)
+	return performance
+}
+
+// valueAt оценивает позиции, купленные не позже heldAt, по ценам на момент pricedAt.
+// Позиции без цены в обоих моментах не учитываются.
+func valueAt(positions []model.Position, series map[string][]model.PricePoint, heldAt, pricedAt int64) (model.Decimal, bool) {
+	var total model.Decimal
+	priced := false
+	for _, position := range positions {
+		if position.AcquiredAt > heldAt {
+			continue
+		}
+		if _, ok := AsOf(series[position.CoinID], heldAt); !ok {
+			continue
+		}
+		price, ok := AsOf(series[position.CoinID], pricedAt)
+		if !ok {
+			continue
+		}
+		total = total.Add(position.Quantity.Mul(price.Price))
+		priced = true
+	}
+	return total.Round(model.DefaultScale), priced
+}
+
+// ComputeMetrics считает показатели по ряду доходностей за равные периоды.
+// periodsPerYear переводит волатильность и Шарп в годовые, riskFree - годовая ставка.
+func ComputeMetrics(returns []float64, periodsPerYear, riskFree float64) Metrics {
+	metrics := Metrics{Periods: len(returns), RiskFree: riskFree}
+	if len(returns) == 0 {
+		return metrics
+	}
+
+	// Просадка считается по накопленному индексу доходности
+	index, peak := 1.0, 1.0
+	for _, r := range returns {
+		index *= 1 + r
+		peak = math.Max(peak, index)
+		metrics.MaxDrawdown = math.Max(metrics.MaxDrawdown, 1-index/peak)
+	}
+	metrics.TotalReturn = index - 1
+
+	stdDev := StdDev(returns)
+	metrics.Volatility = stdDev * math.Sqrt(periodsPerYear)
+	if stdDev > 0 {
+		excess := Mean(returns) - riskFree/periodsPerYear
+		sharpe := excess / stdDev * math.Sqrt(periodsPerYear)
+		metrics.Sharpe = &sharpe
+	}
+	return metrics
+}
@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"cryptoObserver/internal/app/model"
+	"fmt"
+	"sort"
+)
+
+// MaxGridPoints ограничивает число узлов сетки в одном запросе
+const MaxGridPoints = 10000
+
+// Grid возвращает узлы from, from+step, ... не позже to
+func Grid(from, to, step int64) ([]int64, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	if from > to {
+		return nil, fmt.Errorf("from is after to")
+	}
+	// Разность в uint64: to-from может не поместиться в int64.
+	// Узлы считаются от числа точек, а не наращиванием t, которое переполнится у MaxInt64
+	steps := uint64(to-from) / uint64(step)
+	if steps >= MaxGridPoints {
+		return nil, fmt.Errorf("too many points, at most %d allowed: increase the step or shorten the period", MaxGridPoints)
+	}
+	grid := make([]int64, steps+1)
+	for i := range grid {
+		grid[i] = from + int64(i)*step
+	}
+	return grid, nil
+}
+
+// AsOf возвращает последнюю точку упорядоченного ряда не позже t
+func AsOf(series []model.PricePoint, t int64) (model.PricePoint, bool) {
+	i := sort.Search(len(series), func(i int) bool { return series[i].Timestamp > t })
+	if i == 0 {
+		return model.PricePoint{}, false
+	}
+	return series[i-1], true
+}
+
+// AlignUp округляет t вверх до кратного step, чтобы узлы сетки шли по границам интервалов
+func AlignUp(t, step int64) int64 {
+	if r := t % step; r != 0 {
+		if t < 0 {
+			return t - r
+		}
+		return t - r + step
+	}
+	return t
+}
+
+// Resample возвращает цены закрытия в узлах grid: последнюю точку ряда не позже узла,
+// с временем узла. Узлы до первой точки ряда пропускаются.
+func Resample(series []model.PricePoint, grid []int64) []model.PricePoint {
+	closes := make([]model.PricePoint, 0, len(grid))
+	for _, t := range grid {
+		if point, ok := AsOf(series, t); ok {
+			closes = append(closes, model.PricePoint{Timestamp: t, Price: point.Price})
+		}
+	}
+	return closes
+}
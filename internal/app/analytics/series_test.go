@@ -0,0 +1,50 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		from, to, step int64
+		want           string
+	}{
+		{0, 10, 5, "[0 5 10]"},
+		{0, 9, 5, "[0 5]"},
+		{7, 7, 60, "[7]"},
+		{-10, 10, 10, "[-10 0 10]"},
+		// Наращивание t += step переполнилось бы и не закончилось
+		{math.MaxInt64 - 10, math.MaxInt64, 4, "[9223372036854775797 9223372036854775801 9223372036854775805]"},
+		{math.MaxInt64 - 1, math.MaxInt64, math.MaxInt64, "[9223372036854775806]"},
+	}
+	for _, tt := range tests {
+		grid, err := Grid(tt.from, tt.to, tt.step)
+		if err != nil {
+			t.Errorf("Grid(%d, %d, %d): %v", tt.from, tt.to, tt.step, err)
+			continue
+		}
+		if got := fmt.Sprint(grid); got != tt.want {
+			t.Errorf("Grid(%d, %d, %d) = %s, want %s", tt.from, tt.to, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestGridRejects(t *testing.T) {
+	tests := []struct {
+		from, to, step int64
+	}{
+		{0, 10, 0},
+		{10, 0, 1},
+		{0, MaxGridPoints, 1},
+		// to-from не помещается в int64
+		{math.MinInt64, math.MaxInt64, 1},
+		{math.MinInt64, math.MaxInt64, math.MaxInt64 / MaxGridPoints},
+	}
+	for _, tt := range tests {
+		if grid, err := Grid(tt.from, tt.to, tt.step); err == nil {
+			t.Errorf("Grid(%d, %d, %d) returned %d points, want error", tt.from, tt.to, tt.step, len(grid))
+		}
+	}
+}
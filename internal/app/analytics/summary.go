@@ -0,0 +1,59 @@
+package analytics
+
+import (
+	"cryptoObserver/internal/app/model"
+	"sort"
+)
+
+// Summary - сводная статистика ряда цен за окно
+type Summary struct {
+	CurrencyID     string         `json:"currency_id"`
+	Window         string         `json:"window"`
+	From           int64          `json:"from"`
+	To             int64          `json:"to"`
+	Count          int            `json:"count"`
+	FirstTimestamp int64          `json:"first_timestamp"`
+	LastTimestamp  int64          `json:"last_timestamp"`
+	First          model.Decimal  `json:"first" swaggertype:"string"`
+	Last           model.Decimal  `json:"last" swaggertype:"string"`
+	Min            model.Decimal  `json:"min" swaggertype:"string"`
+	Max            model.Decimal  `json:"max" swaggertype:"string"`
+	Mean           model.Decimal  `json:"mean" swaggertype:"string"`
+	Median         model.Decimal  `json:"median" swaggertype:"string"`
+	StdDev         float64        `json:"stddev"`                              // Выборочное стандартное отклонение
+	ChangePercent  *model.Decimal `json:"change_percent" swaggertype:"string"` // Изменение последней цены к первой, %
+}
+
+// Summarize считает статистику упорядоченного непустого ряда series
+func Summarize(series []model.PricePoint) Summary {
+	first, last := series[0], series[len(series)-1]
+	summary := Summary{
+		Count:          len(series),
+		FirstTimestamp: first.Timestamp,
+		LastTimestamp:  last.Timestamp,
+		First:          first.Price,
+		Last:           last.Price,
+	}
+
+	prices := make([]model.Decimal, len(series))
+	values := make([]float64, len(series))
+	var sum model.Decimal
+	for i, point := range series {
+		prices[i] = point.Price
+		values[i] = point.Price.Float64()
+		sum = sum.Add(point.Price)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+
+	count := model.NewDecimal(int64(len(prices)), 0)
+	summary.Min, summary.Max = prices[0], prices[len(prices)-1]
+	summary.Mean = sum.Div(count, model.DefaultScale)
+	if middle := len(prices) / 2; len(prices)%2 == 1 {
+		summary.Median = prices[middle]
+	} else {
+		summary.Median = prices[middle-1].Add(prices[middle]).Div(model.NewDecimal(2, 0), model.DefaultScale)
+	}
+	summary.StdDev = StdDev(values)
+	summary.ChangePercent = model.PercentOf(last.Price.Sub(first.Price), first.Price)
+	return summary
+}
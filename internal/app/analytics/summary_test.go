@@ -0,0 +1,78 @@
+package analytics
+
+import (
+	"cryptoObserver/internal/app/model"
+	"math"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []string // Цены по порядку времени
+		min    string
+		max    string
+		mean   string
+		median string
+		stddev float64
+		change string // Пусто - изменение не определено
+	}{
+		{"single", []string{"5"}, "5", "5", "5", "5", 0, "0"},
+		{"odd", []string{"3", "1", "2"}, "1", "3", "2", "2", 1, "-33.3333"},
+		{"even", []string{"1", "4", "2", "3"}, "1", "4", "2.5", "2.5", math.Sqrt(5.0 / 3), "200"},
+		// Выборочное отклонение: sqrt(32/7), а не 2, как у генеральной совокупности
+		{"sample stddev", []string{"2", "4", "4", "4", "5", "5", "7", "9"}, "2", "9", "5", "4.5", math.Sqrt(32.0 / 7), "350"},
+		// Процент к нулевой первой цене не определен
+		{"zero first price", []string{"0", "10"}, "0", "10", "5", "5", math.Sqrt(50), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := make([]model.PricePoint, len(tt.prices))
+			for i, price := range tt.prices {
+				series[i] = model.PricePoint{Timestamp: int64(100 * (i + 1)), Price: decimal(t, price)}
+			}
+			summary := Summarize(series)
+
+			if summary.Count != len(series) || summary.FirstTimestamp != 100 || summary.LastTimestamp != int64(100*len(series)) {
+				t.Errorf("count %d from %d to %d", summary.Count, summary.FirstTimestamp, summary.LastTimestamp)
+			}
+			if summary.First.Cmp(series[0].Price) != 0 || summary.Last.Cmp(series[len(series)-1].Price) != 0 {
+				t.Errorf("first %s, last %s", summary.First, summary.Last)
+			}
+			for _, check := range []struct {
+				name string
+				got  model.Decimal
+				want string
+			}{
+				{"min", summary.Min, tt.min},
+				{"max", summary.Max, tt.max},
+				{"mean", summary.Mean, tt.mean},
+				{"median", summary.Median, tt.median},
+			} {
+				if check.got.Cmp(decimal(t, check.want)) != 0 {
+					t.Errorf("%s = %s, want %s", check.name, check.got, check.want)
+				}
+			}
+			if math.Abs(summary.StdDev-tt.stddev) > 1e-9 {
+				t.Errorf("stddev = %v, want %v", summary.StdDev, tt.stddev)
+			}
+			switch {
+			case tt.change == "" && summary.ChangePercent != nil:
+				t.Errorf("change = %s, want undefined", summary.ChangePercent)
+			case tt.change != "" && summary.ChangePercent == nil:
+				t.Errorf("change undefined, want %s", tt.change)
+			case tt.change != "" && summary.ChangePercent.Cmp(decimal(t, tt.change)) != 0:
+				t.Errorf("change = %s, want %s", summary.ChangePercent, tt.change)
+			}
+		})
+	}
+}
+
+func decimal(t *testing.T, s string) model.Decimal {
+	t.Helper()
+	d, err := model.ParseDecimal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
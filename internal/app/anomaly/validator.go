@@ -0,0 +1,178 @@
+// Package anomaly проверяет входящие цены до записи в историю.
+// Подозрительные точки не пишутся в currency_prices, а уходят в карантин на ручную проверку.
+package anomaly
+
+import (
+	"cryptoObserver/internal/app/model"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Причины попадания точки в карантин
+const (
+	ReasonNonPositive = "non_positive" // Цена 0 или отрицательная
+	ReasonJump        = "jump"         // Скачок относительно скользящей медианы
+	ReasonStale       = "stale"        // Повтор предыдущей точки провайдера
+)
+
+// minHistory - сколько принятых точек нужно, чтобы проверять скачки
+const minHistory = 3
+
+// Rules - правила проверки. Нулевое значение правила выключает его.
+type Rules struct {
+	RejectNonPositive bool    // Отклонять цену <= 0
+	MaxJumpPercent    float64 // Допустимое отклонение от медианы последних точек, %
+	MedianWindow      int     // Сколько последних принятых точек входит в медиану
+	RejectStale       bool    // Отклонять повтор цены с тем же временем обновления у провайдера
+}
+
+// Sample - входящая точка цены
+type Sample struct {
+	Price         model.Decimal
+	Timestamp     int64
+	SourceUpdated string // Время обновления цены у провайдера, если он его сообщает
+}
+
+// history - последние принятые точки валюты
+type history struct {
+	prices      []model.Decimal // Не длиннее MedianWindow, от старых к новым
+	rejected    []model.Decimal // Подряд отклоненные как скачок цены
+	lastPrice   model.Decimal
+	lastUpdated string
+}
+
+// Validator хранит скользящую историю принятых цен по валютам.
+// Безопасен для использования из нескольких воркеров.
+type Validator struct {
+	rules Rules
+
+	mu      sync.Mutex
+	history map[string]*history
+}
+
+func NewValidator(rules Rules) *Validator {
+	if rules.MedianWindow < minHistory {
+		rules.MedianWindow = minHistory
+	}
+	return &Validator{
+		rules:   rules,
+		history: make(map[string]*history),
+	}
+}
+
+// Known сообщает, есть ли у валидатора история валюты
+func (v *Validator) Known(coin string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, ok := v.history[coin]
+	return ok
+}
+
+// Seed задает историю валюты из уже сохраненных цен (от старых к новым),
+// чтобы после перезапуска скачки проверялись сразу
+func (v *Validator) Seed(coin string, prices []model.Decimal) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	h := &history{}
+	for _, price := range prices {
+		h.push(price, v.rules.MedianWindow)
+	}
+	v.history[coin] = h
+}
+
+// Check возвращает причину отклонения точки и пояснение или пустые строки, если точка в порядке.
+// Если цена действительно сменила уровень, скачками отклонялись бы все следующие точки.
+// Поэтому после minHistory отклоненных подряд точек, согласованных между собой,
+// история переносится на новый уровень и точка принимается.
+func (v *Validator) Check(coin string, sample Sample) (reason string, detail string) {
+	if v.rules.RejectNonPositive && sample.Price.Sign() <= 0 {
+		return ReasonNonPositive, fmt.Sprintf("price %s is not positive", sample.Price)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	h, ok := v.history[coin]
+	if !ok {
+		return "", ""
+	}
+	if v.rules.RejectStale && sample.SourceUpdated != "" &&
+		sample.SourceUpdated == h.lastUpdated && sample.Price.Cmp(h.lastPrice) == 0 {
+		return ReasonStale, fmt.Sprintf("provider has not updated the price since %s", sample.SourceUpdated)
+	}
+	if v.rules.MaxJumpPercent > 0 && len(h.prices) >= minHistory {
+		median := h.median()
+		if median.Sign() > 0 {
+			deviation := sample.Price.Sub(median).Abs().Float64() / median.Float64() * 100
+			if deviation > v.rules.MaxJumpPercent {
+				if h.rebase(sample.Price, v.rules) {
+					return "", ""
+				}
+				return ReasonJump, fmt.Sprintf("price %s deviates %.2f%% from rolling median %s", sample.Price, deviation, median)
+			}
+		}
+	}
+	return "", ""
+}
+
+// Accept добавляет принятую точку в историю валюты
+func (v *Validator) Accept(coin string, sample Sample) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	h, ok := v.history[coin]
+	if !ok {
+		h = &history{}
+		v.history[coin] = h
+	}
+	h.push(sample.Price, v.rules.MedianWindow)
+	h.lastUpdated = sample.SourceUpdated
+	h.rejected = nil
+}
+
+// Forget удаляет историю валюты, снятой с отслеживания
+func (v *Validator) Forget(coin string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.history, coin)
+}
+
+func (h *history) push(price model.Decimal, window int) {
+	h.prices = append(h.prices, price)
+	if len(h.prices) > window {
+		h.prices = h.prices[len(h.prices)-window:]
+	}
+	h.lastPrice = price
+}
+
+// rebase запоминает отклоненную цену и переносит историю на новый уровень,
+// если последние отклоненные точки не расходятся между собой
+func (h *history) rebase(price model.Decimal, rules Rules) bool {
+	if len(h.rejected) >= minHistory {
+		median := median(h.rejected)
+		if median.Sign() > 0 && price.Sub(median).Abs().Float64()/median.Float64()*100 <= rules.MaxJumpPercent {
+			h.prices = h.rejected
+			h.rejected = nil
+			return true
+		}
+	}
+	h.rejected = append(h.rejected, price)
+	if len(h.rejected) > rules.MedianWindow {
+		h.rejected = h.rejected[len(h.rejected)-rules.MedianWindow:]
+	}
+	return false
+}
+
+func (h *history) median() model.Decimal {
+	return median(h.prices)
+}
+
+// median возвращает медиану цен
+func median(prices []model.Decimal) model.Decimal {
+	sorted := append([]model.Decimal(nil), prices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return sorted[middle-1].Add(sorted[middle]).Div(model.NewDecimal(2, 0), model.DefaultScale)
+}
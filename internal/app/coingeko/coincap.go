@@ -0,0 +1,96 @@
+package coingecko
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CoinCapClient - второй провайдер цен (CoinCap API v3).
+// Id валют CoinCap совпадают с id CoinGecko для основных монет.
+type CoinCapClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// coinCapAsset - ответ CoinCap на запрос /assets/{id}; числа приходят строками
+type coinCapAsset struct {
+	Data struct {
+		ID                string         `json:"id"`
+		Symbol            string         `json:"symbol"`
+		Name              string         `json:"name"`
+		PriceUsd          model.Decimal  `json:"priceUsd"`
+		MarketCapUsd      *model.Decimal `json:"marketCapUsd"`
+		VolumeUsd24Hr     *model.Decimal `json:"volumeUsd24Hr"`
+		ChangePercent24Hr *model.Decimal `json:"changePercent24Hr"`
+		Supply            *model.Decimal `json:"supply"`
+	} `json:"data"`
+	Timestamp int64 `json:"timestamp"` // мс
+}
+
+// NewCoinCapClient создает клиент CoinCap API
+func NewCoinCapClient(apiKey string) *CoinCapClient {
+	return &CoinCapClient{
+		baseURL: "https://rest.coincap.io/v3",
+		apiKey:  apiKey,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// GetCryptoPrice получает текущую цену криптовалюты по ее ID
+func (c *CoinCapClient) GetCryptoPrice(ctx context.Context, id string) (*CryptoPriceResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/assets/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Add("accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Add("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("request canceled: %w", ctx.Err())
+		default:
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("crypto with id %s not found", id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var asset coinCapAsset
+	if err := json.NewDecoder(resp.Body).Decode(&asset); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if asset.Data.ID == "" {
+		return nil, fmt.Errorf("crypto with id %s not found", id)
+	}
+
+	return &CryptoPriceResponse{
+		ID:                       asset.Data.ID,
+		Symbol:                   asset.Data.Symbol,
+		Name:                     asset.Data.Name,
+		CurrentPrice:             asset.Data.PriceUsd,
+		MarketCap:                asset.Data.MarketCapUsd,
+		TotalVolume:              asset.Data.VolumeUsd24Hr,
+		PriceChangePercentage24h: asset.Data.ChangePercent24Hr,
+		CirculatingSupply:        asset.Data.Supply,
+		LastUpdated:              time.UnixMilli(asset.Timestamp).UTC().Format(time.RFC3339),
+	}, nil
+}
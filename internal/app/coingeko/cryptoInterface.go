@@ -22,11 +22,33 @@ type CoinGeckoClient struct {
 
 // CryptoPriceResponse представляет структуру ответа от API CoinGecko
 type CryptoPriceResponse struct {
-	ID           string        `json:"id"`
-	Symbol       string        `json:"symbol"`
-	Name         string        `json:"name"`
-	CurrentPrice model.Decimal `json:"current_price"`
-	LastUpdated  string        `json:"last_updated"`
+	ID                       string         `json:"id"`
+	Symbol                   string         `json:"symbol"`
+	Name                     string         `json:"name"`
+	CurrentPrice             model.Decimal  `json:"current_price"`
+	MarketCap                *model.Decimal `json:"market_cap"`
+	TotalVolume              *model.Decimal `json:"total_volume"`
+	High24h                  *model.Decimal `json:"high_24h"`
+	Low24h                   *model.Decimal `json:"low_24h"`
+	PriceChangePercentage24h *model.Decimal `json:"price_change_percentage_24h"`
+	CirculatingSupply        *model.Decimal `json:"circulating_supply"`
+	ATH                      *model.Decimal `json:"ath"`
+	LastUpdated              string         `json:"last_updated"`
+}
+
+// Snapshot возвращает рыночные данные из ответа на момент timestamp
+func (r *CryptoPriceResponse) Snapshot(timestamp int64) model.MarketSnapshot {
+	return model.MarketSnapshot{
+		Timestamp:                timestamp,
+		Price:                    r.CurrentPrice,
+		MarketCap:                r.MarketCap,
+		TotalVolume:              r.TotalVolume,
+		High24h:                  r.High24h,
+		Low24h:                   r.Low24h,
+		PriceChangePercentage24h: r.PriceChangePercentage24h,
+		CirculatingSupply:        r.CirculatingSupply,
+		ATH:                      r.ATH,
+	}
 }
 
 // NewCoinGeckoClient создает новый клиент для CoinGecko API
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	worker "cryptoObserver/internal/app/workers"
+	"database/sql"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// NewAcceptQuarantinedHandler godoc
+//
+// @Summary Принять цену из карантина
+// @Description Записывает цену из карантина в историю, несмотря на проверку аномалий, и удаляет ее из карантина.
+// @Description Валюта должна отслеживаться.
+// @Tags quarantine
+// @Produce json
+// @Param id path int true "ID записи карантина"
+// @Success 200 {object} string "OK - Price accepted"
+// @Failure 400 {object} string "Bad Request - Invalid quarantine ID"
+// @Failure 404 {object} string "Not Found - Quarantined price not found"
+// @Failure 409 {object} string "Conflict - Currency is not tracked"
+// @Router /quarantine/{id}/accept [post]
+func NewAcceptQuarantinedHandler(log *logrus.Logger, store sqlstore.QuarantineInterface, currencies sqlstore.CurrencyInterface, pool *worker.WorkerPool) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.acceptQuarantined.NewAcceptQuarantinedHandler"
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid quarantine ID")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid quarantine ID: "+err.Error())
+			return
+		}
+		price, err := store.GetQuarantined(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			log.WithFields(logrus.Fields{
+				"path":         path,
+				"quarantineID": id,
+			}).Warn("Quarantined price not found")
+			utils.Respond(w, r, http.StatusNotFound, "Quarantined price not found")
+			return
+		}
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get quarantined price from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get quarantined price from store: "+err.Error())
+			return
+		}
+		// С буфером записи UpdatePrice не сообщает о неотслеживаемой валюте, а сброс буфера
+		// молча отбросит ее цену, поэтому валюта проверяется до записи и удаления из карантина
+		tracked, err := currencies.GetCurrencyList(r.Context())
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get currency list from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get currency list from store: "+err.Error())
+			return
+		}
+		if !slices.Contains(tracked, price.CoinID) {
+			log.WithFields(logrus.Fields{
+				"path":       path,
+				"currencyID": price.CoinID,
+			}).Warn("Currency is not tracked")
+			utils.Respond(w, r, http.StatusConflict, "Currency is not tracked: "+price.CoinID)
+			return
+		}
+		err = currencies.UpdatePrice(r.Context(), price.CoinID, price.Price, price.Timestamp, model.SourceQuarantine)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Валюту удалили между проверкой и записью
+			log.WithFields(logrus.Fields{
+				"path":       path,
+				"currencyID": price.CoinID,
+			}).Warn("Currency is not tracked")
+			utils.Respond(w, r, http.StatusConflict, "Currency is not tracked: "+price.CoinID)
+			return
+		}
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to save price")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to save price: "+err.Error())
+			return
+		}
+		pool.AcceptQuarantined(price.CoinID, price.Price)
+		if err := store.DeleteQuarantined(r.Context(), id); err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to delete quarantined price")
+			utils.Respond(w, r, http.StatusInternalServerError, "Price saved, but failed to delete it from quarantine: "+err.Error())
+			return
+		}
+		log.WithFields(logrus.Fields{
+			"path":         path,
+			"quarantineID": id,
+			"currencyID":   price.CoinID,
+		}).Info("Quarantined price accepted")
+		utils.Respond(w, r, http.StatusOK, "Price accepted")
+
+	}
+}
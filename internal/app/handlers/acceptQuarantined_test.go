@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"cryptoObserver/internal/app/anomaly"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/memstore"
+	"cryptoObserver/internal/app/store/sqlstore"
+	worker "cryptoObserver/internal/app/workers"
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// bufferedCurrencies, как буфер записи, принимает любую цену без ошибки
+// и только потом пишет ее, если валюта отслеживается
+type bufferedCurrencies struct {
+	sqlstore.CurrencyInterface
+}
+
+func (c bufferedCurrencies) UpdatePrice(ctx context.Context, coin string, price model.Decimal, timestamp int64, source string) error {
+	_ = c.CurrencyInterface.UpdatePrice(ctx, coin, price, timestamp, source)
+	return nil
+}
+
+func acceptQuarantined(t *testing.T, store sqlstore.StoreInterface, validator *anomaly.Validator, id int64) int {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	pool := worker.NewWorkerPool(context.Background(), worker.Source{}, store, 1, time.Minute, time.Minute, log)
+	if validator != nil {
+		pool.SetValidator(validator)
+	}
+	router := chi.NewRouter()
+	router.Post("/quarantine/{id}/accept", NewAcceptQuarantinedHandler(log, store.Quarantine(), bufferedCurrencies{store.Currency()}, pool))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/quarantine/%d/accept", id), nil))
+	return recorder.Code
+}
+
+func TestAcceptQuarantinedUntrackedWithBuffer(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.New()
+	quarantined, err := store.Quarantine().AddQuarantined(ctx, model.QuarantinedPrice{
+		CoinID: "bitcoin", Price: model.NewDecimal(100, 0), Timestamp: 100, Reason: "jump",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := acceptQuarantined(t, store, nil, quarantined.ID); code != http.StatusConflict {
+		t.Fatalf("status for untracked currency = %d, want 409", code)
+	}
+	// Запись остается в карантине, чтобы ее можно было принять после добавления валюты
+	if _, err := store.Quarantine().GetQuarantined(ctx, quarantined.ID); err != nil {
+		t.Fatalf("quarantined price was deleted: %v", err)
+	}
+
+	if err := store.Currency().AddCurrency(ctx, "bitcoin"); err != nil {
+		t.Fatal(err)
+	}
+	if code := acceptQuarantined(t, store, nil, quarantined.ID); code != http.StatusOK {
+		t.Fatalf("status for tracked currency = %d, want 200", code)
+	}
+	point, err := store.Currency().GetNearestPrice(ctx, "bitcoin", 100)
+	if err != nil || point.Timestamp != 100 {
+		t.Fatalf("accepted price not saved: %+v, %v", point, err)
+	}
+	if _, err := store.Quarantine().GetQuarantined(ctx, quarantined.ID); err == nil {
+		t.Fatalf("accepted price is still in quarantine")
+	}
+}
+
+func TestAcceptQuarantinedResetsValidator(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.New()
+	if err := store.Currency().AddCurrency(ctx, "bitcoin"); err != nil {
+		t.Fatal(err)
+	}
+	validator := anomaly.NewValidator(anomaly.Rules{MaxJumpPercent: 10, MedianWindow: 5})
+	validator.Seed("bitcoin", []model.Decimal{model.NewDecimal(100, 0), model.NewDecimal(101, 0), model.NewDecimal(99, 0)})
+
+	// Цена сменила уровень, оператор принимает первую точку нового уровня
+	quarantined, err := store.Quarantine().AddQuarantined(ctx, model.QuarantinedPrice{
+		CoinID: "bitcoin", Price: model.NewDecimal(200, 0), Timestamp: 100, Reason: anomaly.ReasonJump,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := acceptQuarantined(t, store, validator, quarantined.ID); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	next := anomaly.Sample{Price: model.NewDecimal(202, 0), Timestamp: 160}
+	if reason, detail := validator.Check("bitcoin", next); reason != "" {
+		t.Fatalf("sample after accepted shift rejected: %s (%s)", reason, detail)
+	}
+}
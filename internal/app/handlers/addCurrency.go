@@ -33,17 +33,17 @@ func NewAddCurrencyHandler(log *logrus.Logger, store sqlstore.CurrencyInterface,
 			utils.Respond(w, r, http.StatusBadRequest, "Currency ID is required")
 			return
 		}
-		pool.AddCurrency(currencyID)
-		err := store.AddCurrency(currencyID)
+		err := store.AddCurrency(r.Context(), currencyID)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"path":  path,
 				"error": err.Error(),
 			}).Error("Failed to add currency to store")
-			pool.RemoveCurrency(currencyID)
 			utils.Respond(w, r, http.StatusInternalServerError, "Failed to add currency: "+err.Error())
 			return
 		}
+		// Пул подтягивает список валют из БД, просим сверить его сразу
+		pool.Refresh()
 		log.WithFields(logrus.Fields{
 			"path":       path,
 			"currencyID": currencyID,
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	worker "cryptoObserver/internal/app/workers"
+	"database/sql"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NewAddPositionHandler godoc
+//
+// @Summary Добавление позиции в портфель
+// @Description Добавление покупки валюты в портфель. Валюта автоматически ставится на отслеживание.
+// @Description costBasis - полная стоимость покупки, а не цена за единицу.
+// @Tags portfolio
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Param coinID formData string true "ID валюты"
+// @Param quantity formData string true "Количество"
+// @Param costBasis formData string true "Стоимость покупки"
+// @Param acquiredAt formData int false "Время покупки, unix timestamp. По умолчанию - текущее"
+// @Success 201 {object} model.Position "Added position"
+// @Failure 400 {object} string "Bad Request - Invalid position"
+// @Failure 404 {object} string "Not Found - Portfolio not found"
+// @Router /portfolios/{id}/positions [post]
+func NewAddPositionHandler(log *logrus.Logger, store sqlstore.PortfolioInterface, currencies sqlstore.CurrencyInterface, pool *worker.WorkerPool) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.addPosition.NewAddPositionHandler"
+		id, err := parsePortfolioID(r)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid portfolio ID")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid portfolio ID: "+err.Error())
+			return
+		}
+		position, err := parsePosition(r)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid position")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid position: "+err.Error())
+			return
+		}
+		// Портфель проверяется до постановки валюты на отслеживание,
+		// чтобы запрос в несуществующий портфель ничего не менял
+		if _, err := store.GetPortfolio(r.Context(), id); errors.Is(err, sql.ErrNoRows) {
+			log.WithFields(logrus.Fields{
+				"path":        path,
+				"portfolioID": id,
+			}).Warn("Portfolio not found")
+			utils.Respond(w, r, http.StatusNotFound, "Portfolio not found")
+			return
+		} else if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get portfolio from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to add position: "+err.Error())
+			return
+		}
+		// Для оценки портфеля нужна история цен: валюта ставится на отслеживание
+		// до сохранения позиции, чтобы при ошибке не осталось позиции без цен
+		if err := currencies.AddCurrency(r.Context(), position.CoinID); err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to add currency to store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to track currency: "+err.Error())
+			return
+		}
+		position, err = store.AddPosition(r.Context(), id, position)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Портфель могли удалить в обход API между проверкой и вставкой
+			log.WithFields(logrus.Fields{
+				"path":        path,
+				"portfolioID": id,
+			}).Warn("Portfolio not found")
+			utils.Respond(w, r, http.StatusNotFound, "Portfolio not found")
+			return
+		}
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to add position to store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to add position: "+err.Error())
+			return
+		}
+		pool.Refresh()
+		log.WithFields(logrus.Fields{
+			"path":        path,
+			"portfolioID": id,
+			"currencyID":  position.CoinID,
+		}).Info("Position added successfully")
+		utils.Respond(w, r, http.StatusCreated, position)
+
+	}
+}
+
+// parsePosition разбирает и проверяет поля позиции из формы
+func parsePosition(r *http.Request) (model.Position, error) {
+	position := model.Position{CoinID: strings.TrimSpace(r.FormValue("coinID"))}
+	if position.CoinID == "" {
+		return model.Position{}, errors.New("coinID is required")
+	}
+	var err error
+	if position.Quantity, err = model.ParseDecimal(r.FormValue("quantity")); err != nil {
+		return model.Position{}, errors.New("quantity: " + err.Error())
+	}
+	if position.Quantity.Sign() <= 0 {
+		return model.Position{}, errors.New("quantity must be positive")
+	}
+	if position.CostBasis, err = model.ParseDecimal(r.FormValue("costBasis")); err != nil {
+		return model.Position{}, errors.New("costBasis: " + err.Error())
+	}
+	if position.CostBasis.Sign() < 0 {
+		return model.Position{}, errors.New("costBasis must not be negative")
+	}
+	if position.AcquiredAt, err = parseTimestamp(r.FormValue("acquiredAt"), time.Now().Unix()); err != nil {
+		return model.Position{}, errors.New("acquiredAt: " + err.Error())
+	}
+	return position, nil
+}
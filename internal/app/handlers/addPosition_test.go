@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"cryptoObserver/internal/app/store/memstore"
+	"cryptoObserver/internal/app/store/sqlstore"
+	worker "cryptoObserver/internal/app/workers"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingCurrencies не может поставить валюту на отслеживание
+type failingCurrencies struct {
+	sqlstore.CurrencyInterface
+}
+
+func (failingCurrencies) AddCurrency(ctx context.Context, coin string) error {
+	return errors.New("database is down")
+}
+
+func postPosition(t *testing.T, store sqlstore.StoreInterface, currencies sqlstore.CurrencyInterface, portfolioID string) *httptest.ResponseRecorder {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	pool := worker.NewWorkerPool(context.Background(), worker.Source{}, store, 1, time.Minute, time.Minute, log)
+	router := chi.NewRouter()
+	router.Post("/portfolios/{id}/positions", NewAddPositionHandler(log, store.Portfolio(), currencies, pool))
+
+	form := url.Values{"coinID": {"matic-network"}, "quantity": {"2"}, "costBasis": {"1.5"}, "acquiredAt": {"100"}}
+	request := httptest.NewRequest(http.MethodPost, "/portfolios/"+portfolioID+"/positions", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAddPositionTracksCoinFirst(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.New()
+	portfolio, err := store.Portfolio().CreatePortfolio(ctx, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ошибка отслеживания - позиция не сохраняется
+	if code := postPosition(t, store, failingCurrencies{store.Currency()}, "1").Code; code != http.StatusInternalServerError {
+		t.Fatalf("status with failing tracking = %d, want 500", code)
+	}
+	got, err := store.Portfolio().GetPortfolio(ctx, portfolio.ID)
+	if err != nil || len(got.Positions) != 0 {
+		t.Fatalf("position persisted although tracking failed: %+v, %v", got.Positions, err)
+	}
+
+	if code := postPosition(t, store, store.Currency(), "1").Code; code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201", code)
+	}
+	got, _ = store.Portfolio().GetPortfolio(ctx, portfolio.ID)
+	list, _ := store.Currency().GetCurrencyList(ctx)
+	if len(got.Positions) != 1 || len(list) != 1 || list[0] != "matic-network" {
+		t.Fatalf("positions %+v, tracked %v", got.Positions, list)
+	}
+}
+
+func TestAddPositionMissingPortfolio(t *testing.T) {
+	store := memstore.New()
+	if code := postPosition(t, store, store.Currency(), "42").Code; code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", code)
+	}
+	// Запрос в несуществующий портфель не ставит валюту на отслеживание
+	if list, _ := store.Currency().GetCurrencyList(context.Background()); len(list) != 0 {
+		t.Fatalf("currency tracked for a missing portfolio: %v", list)
+	}
+}
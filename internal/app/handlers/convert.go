@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// conversionScale - точность курса и результата: курс дешевой валюты к дорогой
+// бывает меньше 1e-8, DefaultScale его обнулил бы
+const conversionScale int32 = 18
+
+// conversionResponse - результат пересчета amount валюты from в валюту to
+type conversionResponse struct {
+	From      string           `json:"from"`
+	To        string           `json:"to"`
+	Amount    model.Decimal    `json:"amount" swaggertype:"string"`
+	Result    model.Decimal    `json:"result" swaggertype:"string"`
+	Rate      model.Decimal    `json:"rate" swaggertype:"string"` // Сколько to за одну from
+	At        int64            `json:"at"`
+	FromPrice model.PricePoint `json:"from_price"` // Цена from в USD и момент точки
+	ToPrice   model.PricePoint `json:"to_price"`
+	Skew      int64            `json:"skew"` // Разрыв между точками двух валют, сек
+}
+
+// NewConvertHandler godoc
+//
+// @Summary Пересчет между валютами
+// @Description Пересчет amount валюты from в валюту to через кросс-курс по USD-ценам, ближайшим к моменту at.
+// @Description В ответе - использованные точки обеих валют и разрыв между ними. Если разрыв больше CONVERT_MAX_SKEW,
+// @Description пересчет отклоняется: цены с разных моментов дают неверный курс.
+// @Tags currency
+// @Produce json
+// @Param from query string true "ID исходной валюты"
+// @Param to query string true "ID целевой валюты"
+// @Param amount query string false "Количество исходной валюты. По умолчанию 1"
+// @Param at query int false "Момент пересчета, unix timestamp. По умолчанию - текущий"
+// @Success 200 {object} handlers.conversionResponse "Conversion result"
+// @Failure 400 {object} string "Bad Request - Invalid parameters"
+// @Failure 404 {object} string "Not Found - No price found for currency"
+// @Failure 422 {object} string "Unprocessable Entity - Prices are too far apart in time"
+// @Router /convert [get]
+func NewConvertHandler(log *logrus.Logger, store sqlstore.CurrencyInterface, maxSkew time.Duration) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.convert.NewConvertHandler"
+		from := strings.TrimSpace(r.FormValue("from"))
+		to := strings.TrimSpace(r.FormValue("to"))
+		if from == "" || to == "" {
+			log.WithFields(logrus.Fields{
+				"path": path,
+			}).Error("Currency IDs are required")
+			utils.Respond(w, r, http.StatusBadRequest, "Both from and to currency IDs are required")
+			return
+		}
+		amount := model.NewDecimal(1, 0)
+		if value := strings.TrimSpace(r.FormValue("amount")); value != "" {
+			var err error
+			if amount, err = model.ParseDecimal(value); err != nil {
+				log.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Error("Invalid amount")
+				utils.Respond(w, r, http.StatusBadRequest, "Invalid amount: "+err.Error())
+				return
+			}
+		}
+		at, err := parseTimestamp(r.FormValue("at"), time.Now().Unix())
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid at timestamp")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid at timestamp: "+err.Error())
+			return
+		}
+
+		response := conversionResponse{From: from, To: to, Amount: amount, At: at}
+		for _, leg := range []struct {
+			coin  string
+			price *model.PricePoint
+		}{{from, &response.FromPrice}, {to, &response.ToPrice}} {
+			*leg.price, err = store.GetNearestPrice(r.Context(), leg.coin, at)
+			if err != nil {
+				log.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Error("Failed to get price from store")
+				utils.Respond(w, r, http.StatusInternalServerError, "Failed to get price from store: "+err.Error())
+				return
+			}
+			if leg.price.Timestamp == 0 || leg.price.Price.IsZero() {
+				log.WithFields(logrus.Fields{
+					"path":       path,
+					"currencyID": leg.coin,
+				}).Warn("No price found for currency")
+				utils.Respond(w, r, http.StatusNotFound, "No price found for currency "+leg.coin)
+				return
+			}
+		}
+
+		response.Skew = response.FromPrice.Timestamp - response.ToPrice.Timestamp
+		if response.Skew < 0 {
+			response.Skew = -response.Skew
+		}
+		if time.Duration(response.Skew)*time.Second > maxSkew {
+			log.WithFields(logrus.Fields{
+				"path": path,
+				"skew": response.Skew,
+			}).Warn("Prices are too far apart in time")
+			utils.Respond(w, r, http.StatusUnprocessableEntity,
+				fmt.Sprintf("Prices are too far apart in time: %ds, at most %ds allowed", response.Skew, int64(maxSkew/time.Second)))
+			return
+		}
+
+		response.Rate = response.FromPrice.Price.Div(response.ToPrice.Price, conversionScale)
+		response.Result = amount.Mul(response.FromPrice.Price).Div(response.ToPrice.Price, conversionScale)
+		utils.Respond(w, r, http.StatusOK, response)
+
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/memstore"
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.New()
+	for _, point := range []struct {
+		coin      string
+		price     string
+		timestamp int64
+	}{
+		{"bitcoin", "60000", 1000},
+		{"ethereum", "3000", 1010},
+		{"dust", "0.000000001", 1000},
+		{"lagging", "1", 400},
+		{"edge", "1", 700},
+		{"broken", "0", 1000},
+	} {
+		price, err := model.ParseDecimal(point.price)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Currency().AddCurrency(ctx, point.coin); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Currency().UpdatePrice(ctx, point.coin, price, point.timestamp, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	handler := NewConvertHandler(log, store.Currency(), 5*time.Minute)
+
+	tests := []struct {
+		name   string
+		query  string
+		code   int
+		rate   string
+		result string
+		fromAt int64
+		toAt   int64
+		skew   int64
+	}{
+		{"cross rate", "from=bitcoin&to=ethereum&amount=2&at=1000", http.StatusOK, "20", "40", 1000, 1010, 10},
+		{"inverse", "from=ethereum&to=bitcoin&at=1010", http.StatusOK, "0.05", "0.05", 1010, 1000, 10},
+		// Курс меньше 1e-8 не обнуляется
+		{"tiny rate", "from=dust&to=bitcoin&amount=3&at=1000", http.StatusOK, "0.000000000000016667", "0.00000000000005", 1000, 1000, 0},
+		{"skew at limit", "from=bitcoin&to=edge&at=1000", http.StatusOK, "60000", "60000", 1000, 700, 300},
+		{"skew too large", "from=bitcoin&to=lagging&at=1000", http.StatusUnprocessableEntity, "", "", 0, 0, 0},
+		// Нулевая цена не доходит до деления
+		{"zero price", "from=bitcoin&to=broken&at=1000", http.StatusNotFound, "", "", 0, 0, 0},
+		{"unknown currency", "from=bitcoin&to=dogecoin&at=1000", http.StatusNotFound, "", "", 0, 0, 0},
+		{"missing to", "from=bitcoin", http.StatusBadRequest, "", "", 0, 0, 0},
+		{"invalid amount", "from=bitcoin&to=ethereum&amount=abc", http.StatusBadRequest, "", "", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler(recorder, httptest.NewRequest(http.MethodGet, "/convert?"+tt.query, nil))
+			if recorder.Code != tt.code {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.code, recorder.Body)
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var response conversionResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			for _, check := range []struct {
+				name      string
+				got, want string
+			}{
+				{"rate", response.Rate.String(), tt.rate},
+				{"result", response.Result.String(), tt.result},
+			} {
+				want, _ := model.ParseDecimal(check.want)
+				if got, _ := model.ParseDecimal(check.got); got.Cmp(want) != 0 {
+					t.Errorf("%s = %s, want %s", check.name, check.got, check.want)
+				}
+			}
+			if response.FromPrice.Timestamp != tt.fromAt || response.ToPrice.Timestamp != tt.toAt || response.Skew != tt.skew {
+				t.Errorf("legs at %d and %d with skew %d, want %d and %d with skew %d",
+					response.FromPrice.Timestamp, response.ToPrice.Timestamp, response.Skew, tt.fromAt, tt.toAt, tt.skew)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+// NewCreatePortfolioHandler godoc
+//
+// @Summary Создание портфеля
+// @Description Создание пустого портфеля. Позиции добавляются через /portfolios/{id}/positions.
+// @Tags portfolio
+// @Accept multipart/form-data
+// @Produce json
+// @Param name formData string true "Название портфеля"
+// @Success 201 {object} model.Portfolio "Created portfolio"
+// @Failure 400 {object} string "Bad Request - Portfolio name is required"
+// @Router /portfolios [post]
+func NewCreatePortfolioHandler(log *logrus.Logger, store sqlstore.PortfolioInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.createPortfolio.NewCreatePortfolioHandler"
+		name := strings.TrimSpace(r.FormValue("name"))
+		if name == "" {
+			log.WithFields(logrus.Fields{
+				"path": path,
+			}).Error("Portfolio name is required")
+			utils.Respond(w, r, http.StatusBadRequest, "Portfolio name is required")
+			return
+		}
+		portfolio, err := store.CreatePortfolio(r.Context(), name)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to create portfolio")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to create portfolio: "+err.Error())
+			return
+		}
+		log.WithFields(logrus.Fields{
+			"path":        path,
+			"portfolioID": portfolio.ID,
+		}).Info("Portfolio created successfully")
+		utils.Respond(w, r, http.StatusCreated, portfolio)
+
+	}
+}
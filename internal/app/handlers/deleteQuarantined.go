@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+// NewDeleteQuarantinedHandler godoc
+//
+// @Summary Отклонить цену из карантина
+// @Description Удаляет цену из карантина, не записывая ее в историю.
+// @Tags quarantine
+// @Produce json
+// @Param id path int true "ID записи карантина"
+// @Success 200 {object} string "OK - Price discarded"
+// @Failure 400 {object} string "Bad Request - Invalid quarantine ID"
+// @Router /quarantine/{id} [delete]
+func NewDeleteQuarantinedHandler(log *logrus.Logger, store sqlstore.QuarantineInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.deleteQuarantined.NewDeleteQuarantinedHandler"
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid quarantine ID")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid quarantine ID: "+err.Error())
+			return
+		}
+		if err := store.DeleteQuarantined(r.Context(), id); err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to delete quarantined price")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to delete quarantined price: "+err.Error())
+			return
+		}
+		log.WithFields(logrus.Fields{
+			"path":         path,
+			"quarantineID": id,
+		}).Info("Quarantined price discarded")
+		utils.Respond(w, r, http.StatusOK, "Price discarded")
+
+	}
+}
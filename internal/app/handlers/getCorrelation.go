@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/analytics"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Значения по умолчанию для корреляции
+const (
+	defaultCorrelationPeriod   = 30 * 24 * time.Hour
+	defaultCorrelationInterval = time.Hour
+	maxCorrelationIDs          = 20
+)
+
+// NewGetCorrelationHandler godoc
+//
+// @Summary Корреляция валют
+// @Description Матрица корреляций Пирсона доходностей валют ids за период. Ряды выравниваются на общую сетку с шагом interval:
+// @Description цена в узле - последняя точка не позже него. Шаг стоит выбирать не меньше периода опроса цен,
+// @Description иначе повторяющиеся цены дают нулевые доходности. По умолчанию - последние 30 дней с шагом 1h.
+// @Tags analytics
+// @Produce json
+// @Param ids query string true "ID валют через запятую, от 2 до 20"
+// @Param from query int false "Начало периода, unix timestamp"
+// @Param to query int false "Конец периода, unix timestamp"
+// @Param interval query string false "Шаг сетки: 5m, 1h, 1d и т.п."
+// @Success 200 {object} analytics.Correlation "Correlation matrix"
+// @Failure 400 {object} string "Bad Request - Invalid parameters"
+// @Router /analytics/correlation [get]
+func NewGetCorrelationHandler(log *logrus.Logger, store sqlstore.CurrencyInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.getCorrelation.NewGetCorrelationHandler"
+		ids, err := parseIDs(r.FormValue("ids"))
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid ids")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid ids: "+err.Error())
+			return
+		}
+		from, to, interval, err := parseRange(r, defaultCorrelationPeriod, defaultCorrelationInterval, "interval")
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid parameters")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid parameters: "+err.Error())
+			return
+		}
+		step := int64(interval / time.Second)
+		grid, err := analytics.Grid(analytics.AlignUp(from, step), to, step)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid parameters")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid parameters: "+err.Error())
+			return
+		}
+		series := make(map[string][]model.PricePoint, len(ids))
+		for _, id := range ids {
+			if series[id], err = loadSeries(r, store, id, from, to); err != nil {
+				log.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Error("Failed to get prices from store")
+				utils.Respond(w, r, http.StatusInternalServerError, "Failed to get prices from store: "+err.Error())
+				return
+			}
+		}
+		utils.Respond(w, r, http.StatusOK, analytics.Correlate(ids, series, grid))
+
+	}
+}
+
+// parseIDs разбирает список id валют через запятую без повторов
+func parseIDs(value string) ([]string, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) < 2 || len(ids) > maxCorrelationIDs {
+		return nil, fmt.Errorf("from 2 to %d distinct currency IDs are required", maxCorrelationIDs)
+	}
+	return ids, nil
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/analytics"
+	"cryptoObserver/internal/app/indicators"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Значения по умолчанию для индикаторов
+const (
+	defaultIndicatorsPeriod   = 7 * 24 * time.Hour
+	defaultIndicatorsInterval = time.Hour
+)
+
+// indicatorPoint - значения линий индикатора на закрытии интервала
+type indicatorPoint struct {
+	Timestamp int64              `json:"timestamp"`
+	Close     model.Decimal      `json:"close" swaggertype:"string"`
+	Values    map[string]float64 `json:"values"`
+}
+
+// indicatorResponse - ряд значений индикатора
+type indicatorResponse struct {
+	CurrencyID string           `json:"currency_id"`
+	Name       string           `json:"name"`
+	Interval   int64            `json:"interval"` // Шаг свечей, сек
+	Points     []indicatorPoint `json:"points"`
+}
+
+// NewGetIndicatorsHandler godoc
+//
+// @Summary Технический индикатор
+// @Description Индикатор по ценам закрытия интервалов interval: sma, ema, rsi, bollinger или macd.
+// @Description Цена закрытия интервала - последняя точка не позже его конца. История для разогрева индикатора
+// @Description подгружается до from, поэтому значения есть с начала периода. По умолчанию - последние 7 дней с шагом 1h.
+// @Description Линии: value для sma, ema и rsi; middle, upper, lower для bollinger; macd, signal, histogram для macd.
+// @Tags currency
+// @Produce json
+// @Param id path string true "ID валюты"
+// @Param name query string true "Индикатор: sma, ema, rsi, bollinger, macd"
+// @Param period query int false "Окно индикатора. По умолчанию 14 для rsi, 20 для остальных"
+// @Param interval query string false "Шаг свечей: 5m, 1h, 1d и т.п."
+// @Param from query int false "Начало периода, unix timestamp"
+// @Param to query int false "Конец периода, unix timestamp"
+// @Param k query number false "Ширина полос Боллинджера в стандартных отклонениях. По умолчанию 2"
+// @Param fast query int false "Быстрая EMA для macd. По умолчанию 12"
+// @Param slow query int false "Медленная EMA для macd. По умолчанию 26"
+// @Param signal query int false "Сигнальная линия macd. По умолчанию 9"
+// @Success 200 {object} handlers.indicatorResponse "Indicator values"
+// @Failure 400 {object} string "Bad Request - Invalid parameters"
+// @Router /currency/{id}/indicators [get]
+func NewGetIndicatorsHandler(log *logrus.Logger, store sqlstore.CurrencyInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.getIndicators.NewGetIndicatorsHandler"
+		currencyID := chi.URLParam(r, "id")
+		name := strings.ToLower(strings.TrimSpace(r.FormValue("name")))
+		params, err := parseIndicatorParams(r)
+		if err == nil {
+			err = params.Validate(name)
+		}
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid indicator parameters")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid indicator parameters: "+err.Error())
+			return
+		}
+		from, to, interval, err := parseRange(r, defaultIndicatorsPeriod, defaultIndicatorsInterval, "interval")
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid parameters")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid parameters: "+err.Error())
+			return
+		}
+		step := int64(interval / time.Second)
+		from = analytics.AlignUp(from, step)
+		// Разогрев: индикатору нужны значения до начала периода
+		loadFrom := from - int64(params.Warmup(name))*step
+		grid, err := analytics.Grid(loadFrom, to, step)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid parameters")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid parameters: "+err.Error())
+			return
+		}
+		series, err := loadSeries(r, store, currencyID, loadFrom, to)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get prices from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get prices from store: "+err.Error())
+			return
+		}
+
+		closes := analytics.Resample(series, grid)
+		values := make([]float64, len(closes))
+		for i, c := range closes {
+			values[i] = c.Price.Float64()
+		}
+		lines, err := indicators.Compute(name, values, params)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to compute indicator")
+			utils.Respond(w, r, http.StatusBadRequest, "Failed to compute indicator: "+err.Error())
+			return
+		}
+
+		response := indicatorResponse{CurrencyID: currencyID, Name: name, Interval: step, Points: []indicatorPoint{}}
+		for i, c := range closes {
+			if c.Timestamp < from || !indicators.Ready(lines, i) {
+				continue
+			}
+			point := indicatorPoint{Timestamp: c.Timestamp, Close: c.Price, Values: make(map[string]float64, len(lines))}
+			for line, series := range lines {
+				point.Values[line] = series[i]
+			}
+			response.Points = append(response.Points, point)
+		}
+		utils.Respond(w, r, http.StatusOK, response)
+
+	}
+}
+
+// parseIndicatorParams разбирает необязательные параметры индикатора
+func parseIndicatorParams(r *http.Request) (indicators.Params, error) {
+	var params indicators.Params
+	for _, field := range []struct {
+		name  string
+		value *int
+	}{{"period", &params.Period}, {"fast", &params.Fast}, {"slow", &params.Slow}, {"signal", &params.Signal}} {
+		value := strings.TrimSpace(r.FormValue(field.name))
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return indicators.Params{}, fmt.Errorf("%s must be a positive int", field.name)
+		}
+		*field.value = n
+	}
+	if value := strings.TrimSpace(r.FormValue("k")); value != "" {
+		k, err := strconv.ParseFloat(value, 64)
+		if err != nil || k <= 0 {
+			return indicators.Params{}, fmt.Errorf("k must be a positive number")
+		}
+		params.K = k
+	}
+	return params, nil
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/analytics"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Значения по умолчанию для динамики портфеля
+const (
+	defaultPerformancePeriod     = 30 * 24 * time.Hour
+	defaultPerformanceResolution = time.Hour
+)
+
+// NewGetPerformanceHandler godoc
+//
+// @Summary Динамика стоимости портфеля
+// @Description Стоимость портфеля на сетке с шагом resolution за период и показатели: доходность, максимальная просадка,
+// @Description годовая волатильность и коэффициент Шарпа. Доходность взвешена по времени: покупки внутри периода не считаются ростом.
+// @Description По умолчанию - последние 30 дней с шагом 1h.
+// @Tags portfolio
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Param from query int false "Начало периода, unix timestamp"
+// @Param to query int false "Конец периода, unix timestamp"
+// @Param resolution query string false "Шаг сетки: 5m, 1h, 1d и т.п."
+// @Param riskFree query number false "Годовая безрисковая ставка для Шарпа, доля. По умолчанию 0"
+// @Success 200 {object} analytics.Performance "Portfolio value series and metrics"
+// @Failure 400 {object} string "Bad Request - Invalid parameters"
+// @Failure 404 {object} string "Not Found - Portfolio not found"
+// @Router /portfolios/{id}/performance [get]
+func NewGetPerformanceHandler(log *logrus.Logger, store sqlstore.PortfolioInterface, currencies sqlstore.CurrencyInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.getPerformance.NewGetPerformanceHandler"
+		id, err := parsePortfolioID(r)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid portfolio ID")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid portfolio ID: "+err.Error())
+			return
+		}
+		from, to, step, err := parseRange(r, defaultPerformancePeriod, defaultPerformanceResolution, "resolution")
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid parameters")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid parameters: "+err.Error())
+			return
+		}
+		grid, err := analytics.Grid(from, to, int64(step/time.Second))
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid parameters")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid parameters: "+err.Error())
+			return
+		}
+		riskFree := 0.0
+		if value := strings.TrimSpace(r.FormValue("riskFree")); value != "" {
+			if riskFree, err = strconv.ParseFloat(value, 64); err != nil {
+				log.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Error("Invalid riskFree")
+				utils.Respond(w, r, http.StatusBadRequest, "Invalid riskFree: "+err.Error())
+				return
+			}
+		}
+		portfolio, err := store.GetPortfolio(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			log.WithFields(logrus.Fields{
+				"path":        path,
+				"portfolioID": id,
+			}).Warn("Portfolio not found")
+			utils.Respond(w, r, http.StatusNotFound, "Portfolio not found")
+			return
+		}
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get portfolio from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get portfolio from store: "+err.Error())
+			return
+		}
+		series := make(map[string][]model.PricePoint)
+		for _, position := range portfolio.Positions {
+			if _, ok := series[position.CoinID]; ok {
+				continue
+			}
+			if series[position.CoinID], err = loadSeries(r, currencies, position.CoinID, from, to); err != nil {
+				log.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Error("Failed to get prices from store")
+				utils.Respond(w, r, http.StatusInternalServerError, "Failed to get prices from store: "+err.Error())
+				return
+			}
+		}
+		utils.Respond(w, r, http.StatusOK, analytics.PortfolioPerformance(portfolio, series, grid, step, riskFree))
+
+	}
+}
+
+// loadSeries читает ряд цен за [from, to] вместе с последней точкой до from,
+// чтобы первый узел сетки было чем оценить
+func loadSeries(r *http.Request, currencies sqlstore.CurrencyInterface, coin string, from, to int64) ([]model.PricePoint, error) {
+	first, err := currencies.GetLastPrice(r.Context(), coin, from)
+	if err != nil {
+		return nil, err
+	}
+	series, err := currencies.GetPriceSeries(r.Context(), coin, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if first.Timestamp != 0 && (len(series) == 0 || series[0].Timestamp != first.Timestamp) {
+		series = append([]model.PricePoint{first}, series...)
+	}
+	return series, nil
+}
+
+// parseRange разбирает период from/to и шаг из параметра stepParam.
+// По умолчанию период заканчивается сейчас и длится defaultPeriod.
+func parseRange(r *http.Request, defaultPeriod, defaultStep time.Duration, stepParam string) (int64, int64, time.Duration, error) {
+	now := time.Now()
+	to, err := parseTimestamp(r.FormValue("to"), now.Unix())
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("to: %w", err)
+	}
+	from, err := parseTimestamp(r.FormValue("from"), to-int64(defaultPeriod/time.Second))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("from: %w", err)
+	}
+	if from > to {
+		return 0, 0, 0, fmt.Errorf("from is after to")
+	}
+	step, err := parseDuration(r.FormValue(stepParam), defaultStep)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("%s: %w", stepParam, err)
+	}
+	return from, to, step, nil
+}
+
+// parseDuration разбирает длительность вида 30s, 5m, 1h или 7d.
+// Пустая строка - значение по умолчанию.
+func parseDuration(value string, defaultValue time.Duration) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultValue, nil
+	}
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int64
+		n, err = strconv.ParseInt(days, 10, 64)
+		if err == nil && n > int64(math.MaxInt64/(24*time.Hour)) {
+			return 0, fmt.Errorf("too long")
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("must be at least 1s")
+	}
+	return d, nil
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRangeBounds(t *testing.T) {
+	tests := []struct {
+		query string
+		ok    bool
+	}{
+		{"from=1700000000&to=1700086400", true},
+		{"from=0&to=253402300799", true},
+		{"to=9223372036854775807", false},
+		{"from=-9223372036854775808&to=0", false},
+		{"from=-1&to=10", false},
+		{"from=0&to=10&resolution=106751d", true},
+		{"from=0&to=10&resolution=106752d", false},
+		{"from=0&to=10&resolution=9999999999999d", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/?"+tt.query, nil)
+		_, _, _, err := parseRange(r, time.Hour, time.Minute, "resolution")
+		if (err == nil) != tt.ok {
+			t.Errorf("parseRange(%s) error = %v, want ok = %v", tt.query, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"database/sql"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+// NewGetPortfolioHandler godoc
+//
+// @Summary Портфель
+// @Description Портфель со всеми позициями.
+// @Tags portfolio
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Success 200 {object} model.Portfolio "Portfolio with positions"
+// @Failure 400 {object} string "Bad Request - Invalid portfolio ID"
+// @Failure 404 {object} string "Not Found - Portfolio not found"
+// @Router /portfolios/{id} [get]
+func NewGetPortfolioHandler(log *logrus.Logger, store sqlstore.PortfolioInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.getPortfolio.NewGetPortfolioHandler"
+		id, err := parsePortfolioID(r)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid portfolio ID")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid portfolio ID: "+err.Error())
+			return
+		}
+		portfolio, err := store.GetPortfolio(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			log.WithFields(logrus.Fields{
+				"path":        path,
+				"portfolioID": id,
+			}).Warn("Portfolio not found")
+			utils.Respond(w, r, http.StatusNotFound, "Portfolio not found")
+			return
+		}
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get portfolio from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get portfolio from store: "+err.Error())
+			return
+		}
+		utils.Respond(w, r, http.StatusOK, portfolio)
+
+	}
+}
+
+// parsePortfolioID разбирает id портфеля из пути запроса
+func parsePortfolioID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+}
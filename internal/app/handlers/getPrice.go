@@ -18,7 +18,7 @@ import (
 // @Produce json
 // @Param currencyID formData string true "ID валюты"
 // @Param timestamp formData string true "timestamp"
-// @Success 200 {object} model.Decimal "Price of the currency"
+// @Success 200 {string} string "Price of the currency as a decimal string"
 // @Failure 400 {object} string "Bad Request - Currency ID is required"
 // @Failure 400 {object} string "Bad Request - Timestamp is required"
 // @Failure 404 {object} string "Not Found - No price found for the given currency and timestamp"
@@ -51,7 +51,7 @@ func NewGetPriceHandler(log *logrus.Logger, store sqlstore.CurrencyInterface) fu
 			utils.Respond(w, r, http.StatusBadRequest, "Invalid timestamp format: "+err.Error())
 			return
 		}
-		result, err := store.GetPrice(currencyID, timestampInt)
+		result, err := store.GetPrice(r.Context(), currencyID, timestampInt)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"path":  path,
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Период по умолчанию, если from не указан
+const defaultSnapshotsPeriod = 24 * time.Hour
+
+// NewGetSnapshotsHandler godoc
+//
+// @Summary Рыночные данные валюты
+// @Description История рыночных данных валюты: цена, капитализация, объем торгов, максимум и минимум за 24ч, изменение цены, предложение и ATH.
+// @Description По умолчанию возвращаются данные за последние сутки.
+// @Tags currency
+// @Produce json
+// @Param currencyID query string true "ID валюты"
+// @Param from query int false "Начало периода, unix timestamp"
+// @Param to query int false "Конец периода, unix timestamp"
+// @Success 200 {array} model.MarketSnapshot "Market snapshots ordered by timestamp"
+// @Failure 400 {object} string "Bad Request - Currency ID is required"
+// @Failure 400 {object} string "Bad Request - Invalid period"
+// @Router /currency/snapshots [get]
+func NewGetSnapshotsHandler(log *logrus.Logger, store sqlstore.MarketInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.getSnapshots.NewGetSnapshotsHandler"
+		currencyID := strings.TrimSpace(r.FormValue("currencyID"))
+		if currencyID == "" {
+			log.WithFields(logrus.Fields{
+				"path": path,
+			}).Error("Currency ID is required")
+			utils.Respond(w, r, http.StatusBadRequest, "Currency ID is required")
+			return
+		}
+		now := time.Now()
+		from, err := parseTimestamp(r.FormValue("from"), now.Add(-defaultSnapshotsPeriod).Unix())
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid from timestamp")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid from timestamp: "+err.Error())
+			return
+		}
+		to, err := parseTimestamp(r.FormValue("to"), now.Unix())
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid to timestamp")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid to timestamp: "+err.Error())
+			return
+		}
+		if from > to {
+			log.WithFields(logrus.Fields{
+				"path": path,
+			}).Error("Invalid period")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid period: from is after to")
+			return
+		}
+		result, err := store.GetSnapshots(r.Context(), currencyID, from, to)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get market snapshots from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get market snapshots from store: "+err.Error())
+			return
+		}
+		utils.Respond(w, r, http.StatusOK, result)
+
+	}
+}
+
+// maxTimestamp - конец 9999 года. Моменты вне [0, maxTimestamp] отклоняются,
+// чтобы арифметика периодов и сеток не переполняла int64
+const maxTimestamp = 253402300799
+
+// parseTimestamp разбирает unix timestamp, пустая строка - значение по умолчанию
+func parseTimestamp(value string, defaultValue int64) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultValue, nil
+	}
+	timestamp, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if timestamp < 0 || timestamp > maxTimestamp {
+		return 0, fmt.Errorf("must be between 0 and %d", maxTimestamp)
+	}
+	return timestamp, nil
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// NewGetSourcePricesHandler godoc
+//
+// @Summary Цены провайдеров валюты
+// @Description Цены от каждого провайдера в режиме консенсуса (в PRICE_SOURCES больше одного провайдера):
+// @Description итоговая цена, отклонение провайдера от нее в процентах и признак превышения порога.
+// @Description По умолчанию возвращаются данные за последние сутки.
+// @Tags currency
+// @Produce json
+// @Param id path string true "ID валюты"
+// @Param from query int false "Начало периода, unix timestamp"
+// @Param to query int false "Конец периода, unix timestamp"
+// @Success 200 {array} model.SourcePrice "Source prices ordered by timestamp"
+// @Failure 400 {object} string "Bad Request - Invalid period"
+// @Router /currency/{id}/sources [get]
+func NewGetSourcePricesHandler(log *logrus.Logger, store sqlstore.SourceInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.getSourcePrices.NewGetSourcePricesHandler"
+		currencyID := chi.URLParam(r, "id")
+		now := time.Now()
+		from, err := parseTimestamp(r.FormValue("from"), now.Add(-defaultSnapshotsPeriod).Unix())
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid from timestamp")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid from timestamp: "+err.Error())
+			return
+		}
+		to, err := parseTimestamp(r.FormValue("to"), now.Unix())
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid to timestamp")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid to timestamp: "+err.Error())
+			return
+		}
+		if from > to {
+			log.WithFields(logrus.Fields{
+				"path": path,
+			}).Error("Invalid period")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid period: from is after to")
+			return
+		}
+		result, err := store.GetSourcePrices(r.Context(), currencyID, from, to)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get source prices from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get source prices from store: "+err.Error())
+			return
+		}
+		utils.Respond(w, r, http.StatusOK, result)
+
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/analytics"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// statsWindows - поддерживаемые окна статистики
+var statsWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// NewGetStatsHandler godoc
+//
+// @Summary Статистика цены валюты
+// @Description Сводка по точкам цены за последнее окно window: минимум, максимум, среднее, медиана,
+// @Description стандартное отклонение, изменение последней цены к первой, время первой и последней точки и их число.
+// @Tags currency
+// @Produce json
+// @Param id path string true "ID валюты"
+// @Param window query string false "Окно: 24h, 7d или 30d. По умолчанию 24h"
+// @Success 200 {object} analytics.Summary "Price statistics"
+// @Failure 400 {object} string "Bad Request - Invalid window"
+// @Failure 404 {object} string "Not Found - No prices in window"
+// @Router /currency/{id}/stats [get]
+func NewGetStatsHandler(log *logrus.Logger, store sqlstore.CurrencyInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.getStats.NewGetStatsHandler"
+		currencyID := chi.URLParam(r, "id")
+		window := strings.TrimSpace(r.FormValue("window"))
+		if window == "" {
+			window = "24h"
+		}
+		period, ok := statsWindows[window]
+		if !ok {
+			log.WithFields(logrus.Fields{
+				"path":   path,
+				"window": window,
+			}).Error("Invalid window")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid window: expected one of 24h, 7d, 30d")
+			return
+		}
+		to := time.Now().Unix()
+		from := to - int64(period/time.Second)
+		series, err := store.GetPriceSeries(r.Context(), currencyID, from, to)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get prices from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get prices from store: "+err.Error())
+			return
+		}
+		if len(series) == 0 {
+			log.WithFields(logrus.Fields{
+				"path":       path,
+				"currencyID": currencyID,
+			}).Warn("No prices in window")
+			utils.Respond(w, r, http.StatusNotFound, "No prices in window")
+			return
+		}
+		summary := analytics.Summarize(series)
+		summary.CurrencyID, summary.Window, summary.From, summary.To = currencyID, window, from, to
+		utils.Respond(w, r, http.StatusOK, summary)
+
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"cryptoObserver/internal/app/analytics"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/memstore"
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetStatsWindow(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.New()
+	if err := store.Currency().AddCurrency(ctx, "bitcoin"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	for _, timestamp := range []int64{now - 3*24*3600, now - 60} {
+		if err := store.Currency().UpdatePrice(ctx, "bitcoin", model.NewDecimal(100, 0), timestamp, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	router := chi.NewRouter()
+	router.Get("/currency/{id}/stats", NewGetStatsHandler(log, store.Currency()))
+
+	tests := []struct {
+		query  string
+		code   int
+		window string
+		count  int
+	}{
+		{"", http.StatusOK, "24h", 1},
+		{"window=24h", http.StatusOK, "24h", 1},
+		{"window=7d", http.StatusOK, "7d", 2},
+		{"window=30d", http.StatusOK, "30d", 2},
+		{"window=1h", http.StatusBadRequest, "", 0},
+		{"window=7D", http.StatusBadRequest, "", 0},
+		{"window=168h", http.StatusBadRequest, "", 0},
+		{"window=abc", http.StatusBadRequest, "", 0},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/currency/bitcoin/stats?"+tt.query, nil))
+		if recorder.Code != tt.code {
+			t.Errorf("%q: status = %d, want %d", tt.query, recorder.Code, tt.code)
+			continue
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+		var summary analytics.Summary
+		if err := json.NewDecoder(recorder.Body).Decode(&summary); err != nil {
+			t.Fatal(err)
+		}
+		if summary.Window != tt.window || summary.Count != tt.count {
+			t.Errorf("%q: window %s with %d points, want %s with %d", tt.query, summary.Window, summary.Count, tt.window, tt.count)
+		}
+	}
+}
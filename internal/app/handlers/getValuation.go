@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"database/sql"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// NewGetValuationHandler godoc
+//
+// @Summary Оценка портфеля
+// @Description Стоимость портфеля на момент at по истории цен: стоимость, прибыль/убыток и доля каждой позиции.
+// @Description Позиция оценивается по последней цене не позже at; позиции, купленные после at, не учитываются.
+// @Description Валюты без цены на этот момент перечислены в unpriced и не входят в итоги.
+// @Tags portfolio
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Param at query int false "Момент оценки, unix timestamp. По умолчанию - текущий"
+// @Success 200 {object} model.Valuation "Portfolio valuation"
+// @Failure 400 {object} string "Bad Request - Invalid portfolio ID"
+// @Failure 400 {object} string "Bad Request - Invalid at timestamp"
+// @Failure 404 {object} string "Not Found - Portfolio not found"
+// @Router /portfolios/{id}/valuation [get]
+func NewGetValuationHandler(log *logrus.Logger, store sqlstore.PortfolioInterface, currencies sqlstore.CurrencyInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.getValuation.NewGetValuationHandler"
+		id, err := parsePortfolioID(r)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid portfolio ID")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid portfolio ID: "+err.Error())
+			return
+		}
+		at, err := parseTimestamp(r.FormValue("at"), time.Now().Unix())
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Invalid at timestamp")
+			utils.Respond(w, r, http.StatusBadRequest, "Invalid at timestamp: "+err.Error())
+			return
+		}
+		portfolio, err := store.GetPortfolio(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			log.WithFields(logrus.Fields{
+				"path":        path,
+				"portfolioID": id,
+			}).Warn("Portfolio not found")
+			utils.Respond(w, r, http.StatusNotFound, "Portfolio not found")
+			return
+		}
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get portfolio from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get portfolio from store: "+err.Error())
+			return
+		}
+		prices := make(map[string]model.PricePoint)
+		for _, position := range portfolio.Positions {
+			if _, ok := prices[position.CoinID]; ok || position.AcquiredAt > at {
+				continue
+			}
+			price, err := currencies.GetLastPrice(r.Context(), position.CoinID, at)
+			if err != nil {
+				log.WithFields(logrus.Fields{
+					"path":  path,
+					"error": err.Error(),
+				}).Error("Failed to get price from store")
+				utils.Respond(w, r, http.StatusInternalServerError, "Failed to get price from store: "+err.Error())
+				return
+			}
+			prices[position.CoinID] = price
+		}
+		utils.Respond(w, r, http.StatusOK, portfolio.Value(at, prices))
+
+	}
+}
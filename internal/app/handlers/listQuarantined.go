@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+// NewListQuarantinedHandler godoc
+//
+// @Summary Цены в карантине
+// @Description Входящие цены, отклоненные проверкой аномалий: цена не больше нуля (non_positive),
+// @Description скачок относительно скользящей медианы (jump) или повтор без обновления у провайдера (stale).
+// @Tags quarantine
+// @Produce json
+// @Param currencyID query string false "ID валюты. По умолчанию - все валюты"
+// @Success 200 {array} model.QuarantinedPrice "Quarantined prices ordered by timestamp"
+// @Router /quarantine [get]
+func NewListQuarantinedHandler(log *logrus.Logger, store sqlstore.QuarantineInterface) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const path = "handlers.listQuarantined.NewListQuarantinedHandler"
+		currencyID := strings.TrimSpace(r.FormValue("currencyID"))
+		result, err := store.ListQuarantined(r.Context(), currencyID)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("Failed to get quarantined prices from store")
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to get quarantined prices from store: "+err.Error())
+			return
+		}
+		utils.Respond(w, r, http.StatusOK, result)
+
+	}
+}
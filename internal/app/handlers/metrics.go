@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"cryptoObserver/internal/app/store/pricecache"
+	"cryptoObserver/internal/app/store/sqlstore/utils"
+	"net/http"
+)
+
+// metricsResponse - метрики сервиса
+type metricsResponse struct {
+	PriceCache *pricecache.Stats `json:"price_cache,omitempty"`
+}
+
+// NewMetricsHandler godoc
+//
+// @Summary Метрики сервиса
+// @Description Счетчики кеша последних цен: попадания, промахи, доля попаданий, число валют в кеше.
+// @Tags service
+// @Produce json
+// @Success 200 {object} handlers.metricsResponse "Metrics"
+// @Router /metrics [get]
+func NewMetricsHandler(cache *pricecache.Store) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response metricsResponse
+		if cache != nil {
+			stats := cache.Stats()
+			response.PriceCache = &stats
+		}
+		utils.Respond(w, r, http.StatusOK, response)
+	}
+}
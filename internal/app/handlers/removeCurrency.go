@@ -31,17 +31,17 @@ func NewRemoveCurrencyHandler(log *logrus.Logger, store sqlstore.CurrencyInterfa
 			utils.Respond(w, r, http.StatusBadRequest, "Currency ID is required")
 			return
 		}
-		pool.RemoveCurrency(currencyID)
-		err := store.RemoveCurrency(currencyID)
+		err := store.RemoveCurrency(r.Context(), currencyID)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"path":  path,
 				"error": err.Error(),
 			}).Error("Failed to remove currency from store")
-			//pool.AddCurrency(currencyID) // Может быть, стоит вернуть валюту в пул, если удаление не удалось?
-			utils.Respond(w, r, http.StatusInternalServerError, "Failed to add currency: "+err.Error())
+			utils.Respond(w, r, http.StatusInternalServerError, "Failed to remove currency: "+err.Error())
 			return
 		}
+		// Пул подтягивает список валют из БД, просим сверить его сразу
+		pool.Refresh()
 		log.WithFields(logrus.Fields{
 			"path":       path,
 			"currencyID": currencyID,
@@ -0,0 +1,115 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// Поддерживаемые индикаторы
+const (
+	NameSMA       = "sma"
+	NameEMA       = "ema"
+	NameRSI       = "rsi"
+	NameBollinger = "bollinger"
+	NameMACD      = "macd"
+)
+
+// Params - параметры индикатора. Нулевые поля заменяются значениями по умолчанию.
+type Params struct {
+	Period int     // Окно SMA, EMA, RSI и полос Боллинджера
+	K      float64 // Ширина полос Боллинджера в стандартных отклонениях
+	Fast   int     // Быстрая EMA в MACD
+	Slow   int     // Медленная EMA в MACD
+	Signal int     // Сигнальная линия MACD
+}
+
+// withDefaults подставляет стандартные значения для индикатора name
+func (p Params) withDefaults(name string) Params {
+	if p.Period == 0 {
+		p.Period = 20
+		if name == NameRSI {
+			p.Period = 14
+		}
+	}
+	if p.K == 0 {
+		p.K = 2
+	}
+	if p.Fast == 0 {
+		p.Fast = 12
+	}
+	if p.Slow == 0 {
+		p.Slow = 26
+	}
+	if p.Signal == 0 {
+		p.Signal = 9
+	}
+	return p
+}
+
+// Validate проверяет имя индикатора и параметры
+func (p Params) Validate(name string) error {
+	p = p.withDefaults(name)
+	switch name {
+	case NameSMA, NameEMA, NameRSI, NameBollinger:
+		if p.Period < 1 {
+			return fmt.Errorf("period must be positive")
+		}
+		if name == NameBollinger && p.K <= 0 {
+			return fmt.Errorf("k must be positive")
+		}
+	case NameMACD:
+		if p.Fast < 1 || p.Signal < 1 || p.Slow <= p.Fast {
+			return fmt.Errorf("macd requires 0 < fast < slow and signal > 0")
+		}
+	default:
+		return fmt.Errorf("unknown indicator %q, expected one of: sma, ema, rsi, bollinger, macd", name)
+	}
+	return nil
+}
+
+// Warmup возвращает число значений, нужных индикатору до первого результата
+func (p Params) Warmup(name string) int {
+	p = p.withDefaults(name)
+	switch name {
+	case NameRSI:
+		return p.Period + 1
+	case NameMACD:
+		return p.Slow + p.Signal - 1
+	default:
+		return p.Period
+	}
+}
+
+// Compute считает индикатор name по ценам закрытия.
+// Возвращает именованные линии индикатора: value для SMA, EMA и RSI,
+// middle/upper/lower для полос Боллинджера, macd/signal/histogram для MACD.
+func Compute(name string, closes []float64, p Params) (map[string][]float64, error) {
+	if err := p.Validate(name); err != nil {
+		return nil, err
+	}
+	p = p.withDefaults(name)
+	switch name {
+	case NameSMA:
+		return map[string][]float64{"value": SMA(closes, p.Period)}, nil
+	case NameEMA:
+		return map[string][]float64{"value": EMA(closes, p.Period)}, nil
+	case NameRSI:
+		return map[string][]float64{"value": RSI(closes, p.Period)}, nil
+	case NameBollinger:
+		middle, upper, lower := Bollinger(closes, p.Period, p.K)
+		return map[string][]float64{"middle": middle, "upper": upper, "lower": lower}, nil
+	default:
+		macd, signal, histogram := MACD(closes, p.Fast, p.Slow, p.Signal)
+		return map[string][]float64{"macd": macd, "signal": signal, "histogram": histogram}, nil
+	}
+}
+
+// Ready сообщает, посчитаны ли все линии в точке i
+func Ready(lines map[string][]float64, i int) bool {
+	for _, line := range lines {
+		if math.IsNaN(line[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+// Package indicators считает технические индикаторы по ряду цен закрытия.
+// Все функции возвращают ряды той же длины, что и вход; значения до окончания
+// разогрева индикатора равны NaN.
+package indicators
+
+import "math"
+
+// nanSeries возвращает ряд длины n, заполненный NaN
+func nanSeries(n int) []float64 {
+	series := make([]float64, n)
+	for i := range series {
+		series[i] = math.NaN()
+	}
+	return series
+}
+
+// SMA - простое скользящее среднее за period значений
+func SMA(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 {
+		return result
+	}
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			result[i] = sum / float64(period)
+		}
+	}
+	return result
+}
+
+// EMA - экспоненциальное скользящее среднее с коэффициентом 2/(period+1).
+// Начальное значение - SMA первых period значений. NaN во входе пропускаются:
+// отсчет начинается с первого числа.
+func EMA(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 {
+		return result
+	}
+	alpha := 2 / float64(period+1)
+	var sum float64
+	count := 0
+	prev := math.NaN()
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		count++
+		if count < period {
+			sum += v
+			continue
+		}
+		if count == period {
+			prev = (sum + v) / float64(period)
+		} else {
+			prev = alpha*v + (1-alpha)*prev
+		}
+		result[i] = prev
+	}
+	return result
+}
+
+// RSI - индекс относительной силы со сглаживанием Уайлдера.
+// Первое значение появляется на индексе period.
+func RSI(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) <= period {
+		return result
+	}
+	var avgGain, avgLoss float64
+	for i := 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		gain, loss := math.Max(change, 0), math.Max(-change, 0)
+		if i <= period {
+			avgGain += gain / float64(period)
+			avgLoss += loss / float64(period)
+			if i < period {
+				continue
+			}
+		} else {
+			avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+		}
+		switch {
+		case avgLoss == 0 && avgGain == 0:
+			result[i] = 50
+		case avgLoss == 0:
+			result[i] = 100
+		default:
+			result[i] = 100 - 100/(1+avgGain/avgLoss)
+		}
+	}
+	return result
+}
+
+// Bollinger - полосы Боллинджера: SMA за period и отклонение на k стандартных
+// отклонений генеральной совокупности за то же окно
+func Bollinger(values []float64, period int, k float64) (middle, upper, lower []float64) {
+	middle = SMA(values, period)
+	upper = nanSeries(len(values))
+	lower = nanSeries(len(values))
+	for i := period - 1; i >= 0 && i < len(values); i++ {
+		var sum float64
+		for _, v := range values[i-period+1 : i+1] {
+			sum += (v - middle[i]) * (v - middle[i])
+		}
+		deviation := math.Sqrt(sum / float64(period))
+		upper[i] = middle[i] + k*deviation
+		lower[i] = middle[i] - k*deviation
+	}
+	return middle, upper, lower
+}
+
+// MACD - разница EMA(fast) и EMA(slow), сигнальная линия EMA(signal) от нее и гистограмма
+func MACD(values []float64, fast, slow, signal int) (macd, signalLine, histogram []float64) {
+	fastEMA := EMA(values, fast)
+	slowEMA := EMA(values, slow)
+	macd = make([]float64, len(values))
+	for i := range values {
+		macd[i] = fastEMA[i] - slowEMA[i]
+	}
+	signalLine = EMA(macd, signal)
+	histogram = make([]float64, len(values))
+	for i := range values {
+		histogram[i] = macd[i] - signalLine[i]
+	}
+	return macd, signalLine, histogram
+}
@@ -0,0 +1,128 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+// emaExample - 10-дневный пример EMA из StockCharts ChartSchool
+var emaExample = []float64{
+	22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+	22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+}
+
+// rsiExample - 14-дневный пример RSI из StockCharts ChartSchool
+var rsiExample = []float64{
+	44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+	45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+	43.42, 42.66, 43.13,
+}
+
+// assertSeries сверяет ряд с эталоном: первые warmup значений - NaN, дальше want с точностью tolerance
+func assertSeries(t *testing.T, name string, got []float64, warmup int, want []float64, tolerance float64) {
+	t.Helper()
+	if len(got) != warmup+len(want) {
+		t.Fatalf("%s: got %d values, want %d", name, len(got), warmup+len(want))
+	}
+	for i := 0; i < warmup; i++ {
+		if !math.IsNaN(got[i]) {
+			t.Errorf("%s[%d] = %v during warmup, want NaN", name, i, got[i])
+		}
+	}
+	for i, w := range want {
+		if g := got[warmup+i]; math.Abs(g-w) > tolerance {
+			t.Errorf("%s[%d] = %.4f, want %.4f", name, warmup+i, g, w)
+		}
+	}
+}
+
+func TestSMA(t *testing.T) {
+	want := []float64{
+		22.221, 22.209, 22.229, 22.259, 22.303, 22.421, 22.613, 22.765, 22.905, 23.076, 23.21,
+		23.377, 23.525, 23.652, 23.71, 23.684, 23.612, 23.505, 23.432, 23.277, 23.131,
+	}
+	assertSeries(t, "SMA", SMA(emaExample, 10), 9, want, 1e-9)
+}
+
+// TestEMA сверяет с таблицей ChartSchool, округленной до сотых
+func TestEMA(t *testing.T) {
+	want := []float64{
+		22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34,
+		23.43, 23.51, 23.54, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+	}
+	assertSeries(t, "EMA", EMA(emaExample, 10), 9, want, 0.01)
+}
+
+// TestRSI сверяет со значениями Уайлдера без промежуточного округления, как у TA-Lib.
+// В таблице ChartSchool средние округлены до сотых, поэтому первое значение там 70.53.
+func TestRSI(t *testing.T) {
+	want := []float64{
+		70.46, 66.25, 66.48, 69.35, 66.29, 57.92, 62.88, 63.21, 56.01, 62.34,
+		54.67, 50.39, 40.02, 41.49, 41.90, 45.50, 37.32, 33.09, 37.79,
+	}
+	assertSeries(t, "RSI", RSI(rsiExample, 14), 14, want, 0.005)
+}
+
+func TestRSIFlatAndMonotonic(t *testing.T) {
+	assertSeries(t, "RSI flat", RSI([]float64{5, 5, 5, 5}, 3), 3, []float64{50}, 0)
+	assertSeries(t, "RSI rising", RSI([]float64{1, 2, 3, 4, 5}, 3), 3, []float64{100, 100}, 0)
+	assertSeries(t, "RSI falling", RSI([]float64{5, 4, 3, 2, 1}, 3), 3, []float64{0, 0}, 0)
+}
+
+// TestBollinger использует пример стандартного отклонения из Википедии:
+// у {2, 4, 4, 4, 5, 5, 7, 9} среднее 5 и отклонение совокупности 2
+func TestBollinger(t *testing.T) {
+	values := []float64{2, 4, 4, 4, 5, 5, 7, 9, 10}
+	middle, upper, lower := Bollinger(values, 8, 2)
+	// Второе окно {4, 4, 4, 5, 5, 7, 9, 10}: среднее 6, отклонение sqrt(40/8)
+	deviation := math.Sqrt(5)
+	assertSeries(t, "middle", middle, 7, []float64{5, 6}, 1e-9)
+	assertSeries(t, "upper", upper, 7, []float64{9, 6 + 2*deviation}, 1e-9)
+	assertSeries(t, "lower", lower, 7, []float64{1, 6 - 2*deviation}, 1e-9)
+}
+
+// TestMACDLinear проверяет MACD на ряде v[i] = i: EMA с затравкой SMA отстает ровно
+// на (period-1)/2, поэтому MACD(12, 26) = 5.5 - 12.5 + 14 = 7, сигнальная линия 7, гистограмма 0
+func TestMACDLinear(t *testing.T) {
+	values := make([]float64, 60)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	macd, signal, histogram := MACD(values, 12, 26, 9)
+	warmup := Params{}.Warmup(NameMACD) - 1
+	want := make([]float64, len(values)-warmup)
+	for i := range want {
+		want[i] = 7
+	}
+	assertSeries(t, "macd", macd[warmup:], 0, want, 1e-9)
+	for i := 25; i < warmup; i++ {
+		if math.Abs(macd[i]-7) > 1e-9 {
+			t.Errorf("macd[%d] = %v, want 7", i, macd[i])
+		}
+	}
+	assertSeries(t, "signal", signal, warmup, want, 1e-9)
+	for i := range want {
+		want[i] = 0
+	}
+	assertSeries(t, "histogram", histogram, warmup, want, 1e-9)
+}
+
+// TestWarmup сверяет Warmup с первым посчитанным значением Compute
+func TestWarmup(t *testing.T) {
+	for _, name := range []string{NameSMA, NameEMA, NameRSI, NameBollinger, NameMACD} {
+		values := make([]float64, 100)
+		for i := range values {
+			values[i] = 100 + math.Sin(float64(i))
+		}
+		lines, err := Compute(name, values, Params{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := Params{}.Warmup(name) - 1
+		if Ready(lines, first-1) || !Ready(lines, first) {
+			t.Errorf("%s: first value is not at index %d", name, first)
+		}
+	}
+}
@@ -3,10 +3,12 @@ package migrations
 import (
 	"database/sql"
 	"embed"
+	"errors"
+	"fmt"
 	_ "github.com/lib/pq"
 	"github.com/pressly/goose/v3"
 	"github.com/sirupsen/logrus"
-	"path"
+	"io/fs"
 )
 
 var (
@@ -14,34 +16,119 @@ var (
 	embedMigrations embed.FS
 )
 
-func MakeMigrations(db *sql.DB, log *logrus.Logger) {
-	dirMigrations := path.Join("migrations")
-	const path = "intenral.app.migrations.migrations.go"
+// ErrSchemaBehind возвращается, если в БД применены не все встроенные миграции
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+// Set - набор миграций одного хранилища со своей таблицей версий
+type Set struct {
+	Name    string
+	fsys    fs.FS
+	dir     string
+	dialect string
+	table   string
+}
+
+// Наборы миграций
+var (
+	Postgres  = Set{Name: "postgres", fsys: embedMigrations, dir: "migrations", dialect: "postgres", table: "goose_db_version"}
+	Timescale = Set{Name: "timescale", fsys: embedTimescale, dir: "timescale", dialect: "postgres", table: "goose_timescale_version"}
+	SQLite    = Set{Name: "sqlite", fsys: embedSQLite, dir: "sqlite", dialect: "sqlite3", table: "goose_db_version"}
+)
+
+// Dir возвращает каталог исходников набора относительно пакета migrations
+func (s Set) Dir() string {
+	return s.dir
+}
+
+// use настраивает глобальное состояние goose на этот набор.
+// goose не потокобезопасен, поэтому миграции выполняются только при старте и из CLI.
+func (s Set) use() error {
+	goose.SetBaseFS(s.fsys)
+	goose.SetTableName(s.table)
+	return goose.SetDialect(s.dialect)
+}
+
+// Up применяет все непримененные миграции
+func (s Set) Up(db *sql.DB) error {
+	if err := s.use(); err != nil {
+		return err
+	}
+	return goose.Up(db, s.dir, goose.WithNoColor(true))
+}
+
+// Down откатывает последнюю примененную миграцию
+func (s Set) Down(db *sql.DB) error {
+	if err := s.use(); err != nil {
+		return err
+	}
+	return goose.Down(db, s.dir)
+}
+
+// Redo откатывает и заново применяет последнюю миграцию
+func (s Set) Redo(db *sql.DB) error {
+	if err := s.use(); err != nil {
+		return err
+	}
+	return goose.Redo(db, s.dir)
+}
+
+// Status выводит в лог состояние каждой миграции
+func (s Set) Status(db *sql.DB) error {
+	if err := s.use(); err != nil {
+		return err
+	}
+	return goose.Status(db, s.dir)
+}
+
+// Version возвращает текущую версию схемы в БД и последнюю встроенную миграцию
+func (s Set) Version(db *sql.DB) (current, latest int64, err error) {
+	if err = s.use(); err != nil {
+		return 0, 0, err
+	}
+	if current, err = goose.GetDBVersion(db); err != nil {
+		return 0, 0, err
+	}
+	migrations, err := goose.CollectMigrations(s.dir, 0, goose.MaxVersion)
+	if err != nil {
+		return 0, 0, err
+	}
+	last, err := migrations.Last()
+	if err != nil {
+		return current, 0, err
+	}
+	return current, last.Version, nil
+}
+
+// CheckVersion возвращает ErrSchemaBehind, если схема в БД старше встроенных миграций
+func (s Set) CheckVersion(db *sql.DB) error {
+	current, latest, err := s.Version(db)
+	if err != nil {
+		return err
+	}
+	if current < latest {
+		return fmt.Errorf("%w: %s version %d, latest %d, run `migrate up`", ErrSchemaBehind, s.Name, current, latest)
+	}
+	return nil
+}
+
+// Create создает в dir новый пустой файл миграции с очередным номером
+func Create(dir, name string) error {
+	goose.SetBaseFS(nil)
+	goose.SetSequential(true)
+	return goose.Create(nil, dir, name, "sql")
+}
+
+// Migrate применяет все непримененные миграции и пишет результат в лог
+func (s Set) Migrate(db *sql.DB, log *logrus.Logger) error {
 	goose.SetLogger(log)
-	goose.SetBaseFS(embedMigrations)
-	if err := goose.SetDialect("postgres"); err != nil {
-		log.Fatal(
-			logrus.WarnLevel,
-			"Ошибка миграций: %v",
-			err,
-		)
-	}
-	options := []goose.OptionsFunc{}
-	options = append(options, goose.WithNoColor(true))
-	options = append(options, goose.WithAllowMissing())
-	options = append(options, goose.WithNoVersioning())
-	if err := goose.Up(db, dirMigrations, options...); err != nil {
-		log.Logf(
-			logrus.WarnLevel,
-			"%v : Ошибка миграций: %v",
-			path,
-			err,
-		)
-		return
+	if err := s.Up(db); err != nil {
+		return err
 	}
 
 	log.Logf(
 		logrus.InfoLevel,
-		"Миграции успешно применены",
+		"Миграции %s успешно применены",
+		s.Name,
 	)
+	return nil
 }
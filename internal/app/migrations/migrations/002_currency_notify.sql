@@ -0,0 +1,25 @@
+-- +goose Up
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_currencies_changed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('currencies_changed', json_build_object('op', 'remove', 'symbol', OLD.symbol)::text);
+        RETURN OLD;
+    END IF;
+    PERFORM pg_notify('currencies_changed', json_build_object('op', 'add', 'symbol', NEW.symbol)::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS currencies_changed ON currencies;
+
+CREATE TRIGGER currencies_changed
+    AFTER INSERT OR DELETE ON currencies
+    FOR EACH ROW EXECUTE FUNCTION notify_currencies_changed();
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS currencies_changed ON currencies;
+DROP FUNCTION IF EXISTS notify_currencies_changed();
@@ -0,0 +1,10 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS worker_members (
+    id VARCHAR(128) PRIMARY KEY,
+    heartbeat_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS worker_members;
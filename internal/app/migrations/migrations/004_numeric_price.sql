@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- Снимаем ограничение DECIMAL(18, 8): точность хранится в model.Decimal.
+-- Меняем тип только один раз: на гипертаблице TimescaleDB колонку
+-- использует непрерывный агрегат, и повторный ALTER завершился бы ошибкой.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'currency_prices'
+          AND column_name = 'price' AND numeric_precision IS NOT NULL
+    ) THEN
+        ALTER TABLE currency_prices ALTER COLUMN price TYPE NUMERIC;
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+
+ALTER TABLE currency_prices ALTER COLUMN price TYPE DECIMAL(18, 8);
@@ -0,0 +1,22 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS market_snapshots (
+    id SERIAL PRIMARY KEY,
+    currency_id INTEGER REFERENCES currencies(id) ON DELETE CASCADE,
+    timestamp BIGINT NOT NULL,
+    price NUMERIC NOT NULL,
+    market_cap NUMERIC,
+    total_volume NUMERIC,
+    high_24h NUMERIC,
+    low_24h NUMERIC,
+    price_change_percentage_24h NUMERIC,
+    circulating_supply NUMERIC,
+    ath NUMERIC,
+    created_at TIMESTAMP DEFAULT NOW(),
+
+    UNIQUE(currency_id, timestamp)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS market_snapshots;
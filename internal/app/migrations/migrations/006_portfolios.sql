@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS portfolios (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+-- Валюта хранится по символу, а не ссылкой на currencies:
+-- удаление валюты из отслеживания не должно стирать позиции
+CREATE TABLE IF NOT EXISTS portfolio_positions (
+    id BIGSERIAL PRIMARY KEY,
+    portfolio_id BIGINT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    coin_id VARCHAR(100) NOT NULL,
+    quantity NUMERIC NOT NULL,
+    cost_basis NUMERIC NOT NULL,
+    acquired_at BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_positions_portfolio ON portfolio_positions (portfolio_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS portfolio_positions;
+DROP TABLE IF EXISTS portfolios;
@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- Цены, отклоненные проверкой аномалий. Валюта хранится по символу,
+-- чтобы карантин не зависел от того, отслеживается ли она сейчас
+CREATE TABLE IF NOT EXISTS quarantined_prices (
+    id BIGSERIAL PRIMARY KEY,
+    coin_id VARCHAR(100) NOT NULL,
+    price NUMERIC NOT NULL,
+    timestamp BIGINT NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantined_prices_coin ON quarantined_prices (coin_id, timestamp);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS quarantined_prices;
@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- Цены отдельных провайдеров в режиме консенсуса и их отклонение от итоговой цены
+CREATE TABLE IF NOT EXISTS source_prices (
+    id BIGSERIAL PRIMARY KEY,
+    coin_id VARCHAR(100) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    price NUMERIC NOT NULL,
+    consensus NUMERIC NOT NULL,
+    deviation_percent NUMERIC NOT NULL,
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    timestamp BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_prices_coin ON source_prices (coin_id, timestamp);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS source_prices;
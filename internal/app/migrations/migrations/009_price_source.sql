@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- Провайдер, от которого получена цена: имя провайдера, consensus или quarantine.
+-- У точек, записанных до появления колонки, источник неизвестен (NULL)
+ALTER TABLE currency_prices ADD COLUMN IF NOT EXISTS source VARCHAR(20);
+
+-- +goose Down
+
+ALTER TABLE currency_prices DROP COLUMN IF EXISTS source;
@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- Id валют CoinGecko бывают длиннее 10 символов (avalanche-2, matic-network).
+-- Таблицы из миграций 006-008 сразу создаются с нужной длиной, расширять остается currencies.
+-- Расширение VARCHAR не переписывает таблицу. В SQLite длина VARCHAR не проверяется,
+-- отдельная миграция там не нужна
+ALTER TABLE currencies ALTER COLUMN symbol TYPE VARCHAR(100);
+
+-- +goose Down
+
+-- Откат не пройдет, если уже сохранены id длиннее 10 символов
+ALTER TABLE currencies ALTER COLUMN symbol TYPE VARCHAR(10);
@@ -0,0 +1,42 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS currencies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol VARCHAR(10) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Цены хранятся строкой, чтобы не терять точность model.Decimal
+CREATE TABLE IF NOT EXISTS currency_prices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    currency_id INTEGER REFERENCES currencies(id) ON DELETE CASCADE,
+    price TEXT NOT NULL,
+    timestamp INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(currency_id, timestamp)
+);
+
+CREATE TABLE IF NOT EXISTS market_snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    currency_id INTEGER REFERENCES currencies(id) ON DELETE CASCADE,
+    timestamp INTEGER NOT NULL,
+    price TEXT NOT NULL,
+    market_cap TEXT,
+    total_volume TEXT,
+    high_24h TEXT,
+    low_24h TEXT,
+    price_change_percentage_24h TEXT,
+    circulating_supply TEXT,
+    ath TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(currency_id, timestamp)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS market_snapshots;
+DROP TABLE IF EXISTS currency_prices;
+DROP TABLE IF EXISTS currencies;
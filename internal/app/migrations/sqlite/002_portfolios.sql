@@ -0,0 +1,24 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS portfolios (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS portfolio_positions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    portfolio_id INTEGER NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    coin_id VARCHAR(10) NOT NULL,
+    quantity TEXT NOT NULL,
+    cost_basis TEXT NOT NULL,
+    acquired_at INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_positions_portfolio ON portfolio_positions (portfolio_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS portfolio_positions;
+DROP TABLE IF EXISTS portfolios;
@@ -0,0 +1,17 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS quarantined_prices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    coin_id VARCHAR(10) NOT NULL,
+    price TEXT NOT NULL,
+    timestamp INTEGER NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantined_prices_coin ON quarantined_prices (coin_id, timestamp);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS quarantined_prices;
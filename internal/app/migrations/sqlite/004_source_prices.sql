@@ -0,0 +1,18 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS source_prices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    coin_id VARCHAR(10) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    price TEXT NOT NULL,
+    consensus TEXT NOT NULL,
+    deviation_percent TEXT NOT NULL,
+    flagged INTEGER NOT NULL DEFAULT 0,
+    timestamp INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_prices_coin ON source_prices (coin_id, timestamp);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS source_prices;
@@ -0,0 +1,7 @@
+-- +goose Up
+
+ALTER TABLE currency_prices ADD COLUMN source VARCHAR(20);
+
+-- +goose Down
+
+ALTER TABLE currency_prices DROP COLUMN source;
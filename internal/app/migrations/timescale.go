@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	//go:embed timescale/*.sql
+	embedTimescale embed.FS
+)
+
+// Режимы поддержки TimescaleDB
+const (
+	TimescaleAuto = "auto" // Включить, если расширение доступно на сервере
+	TimescaleOn   = "on"   // Обязательно, ошибка если расширение недоступно
+	TimescaleOff  = "off"  // Обычный Postgres
+)
+
+// TimescaleEnabled решает по режиму mode, нужна ли схема TimescaleDB:
+// в режиме auto - если расширение доступно на сервере.
+func TimescaleEnabled(db *sql.DB, log *logrus.Logger, mode string) (bool, error) {
+	const path = "internal.app.migrations.timescale.go"
+	if mode == TimescaleOff {
+		return false, nil
+	}
+
+	var available bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'timescaledb')").Scan(&available)
+	if err != nil {
+		return false, err
+	}
+	if !available {
+		if mode == TimescaleOn {
+			return false, fmt.Errorf("timescaledb extension is not available on the server")
+		}
+		log.Infof("%v : TimescaleDB недоступна, используются обычные таблицы", path)
+		return false, nil
+	}
+	return true, nil
+}
+
+// MakeTimescaleMigrations превращает currency_prices в гипертаблицу TimescaleDB
+// и создает непрерывные агрегаты свечей, если расширение доступно.
+// Без расширения сервис продолжает работать на обычных таблицах.
+// Возвращает true, если гипертаблица включена.
+func MakeTimescaleMigrations(db *sql.DB, log *logrus.Logger, mode string) (bool, error) {
+	const path = "internal.app.migrations.timescale.go"
+	enabled, err := TimescaleEnabled(db, log, mode)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	// Своя таблица версий: набор применяется независимо от основной схемы
+	if err := Timescale.Up(db); err != nil {
+		if mode == TimescaleOn {
+			return false, err
+		}
+		log.Warnf("%v : Не удалось включить TimescaleDB, используются обычные таблицы: %v", path, err)
+		return false, nil
+	}
+
+	log.Info("TimescaleDB включена: currency_prices - гипертаблица, свечи в currency_candles_1h/1d")
+	return true, nil
+}
@@ -0,0 +1,71 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+-- Все уникальные ключи гипертаблицы должны включать колонку партиционирования,
+-- поэтому суррогатный первичный ключ снимаем: строку однозначно задает UNIQUE(currency_id, timestamp)
+ALTER TABLE currency_prices DROP CONSTRAINT IF EXISTS currency_prices_pkey;
+
+SELECT create_hypertable(
+    'currency_prices', 'timestamp',
+    chunk_time_interval => 604800,
+    if_not_exists => TRUE,
+    migrate_data => TRUE
+);
+
+-- timestamp хранится в unix-секундах, Timescale нужна функция текущего времени в тех же единицах
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION currency_prices_unix_now() RETURNS BIGINT
+LANGUAGE SQL STABLE AS $$
+    SELECT EXTRACT(EPOCH FROM NOW())::BIGINT
+$$;
+-- +goose StatementEnd
+
+SELECT set_integer_now_func('currency_prices', 'currency_prices_unix_now', replace_if_exists => TRUE);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS currency_candles_1h
+WITH (timescaledb.continuous) AS
+SELECT currency_id,
+       time_bucket(3600, timestamp) AS bucket,
+       first(price, timestamp) AS open,
+       max(price) AS high,
+       min(price) AS low,
+       last(price, timestamp) AS close,
+       count(*) AS samples
+FROM currency_prices
+GROUP BY currency_id, bucket
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('currency_candles_1h',
+    start_offset => 259200,
+    end_offset => 3600,
+    schedule_interval => INTERVAL '30 minutes',
+    if_not_exists => TRUE
+);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS currency_candles_1d
+WITH (timescaledb.continuous) AS
+SELECT currency_id,
+       time_bucket(86400, timestamp) AS bucket,
+       first(price, timestamp) AS open,
+       max(price) AS high,
+       min(price) AS low,
+       last(price, timestamp) AS close,
+       count(*) AS samples
+FROM currency_prices
+GROUP BY currency_id, bucket
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('currency_candles_1d',
+    start_offset => 2592000,
+    end_offset => 86400,
+    schedule_interval => INTERVAL '6 hours',
+    if_not_exists => TRUE
+);
+
+-- +goose Down
+
+-- Преобразование в гипертаблицу необратимо, удаляем только агрегаты
+DROP MATERIALIZED VIEW IF EXISTS currency_candles_1d;
+DROP MATERIALIZED VIEW IF EXISTS currency_candles_1h;
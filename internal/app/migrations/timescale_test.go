@@ -0,0 +1,149 @@
+package migrations_test
+
+import (
+	"context"
+	"cryptoObserver/internal/app/migrations"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/storetest"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"io"
+	"testing"
+	"time"
+)
+
+func quietLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+// seedPrices создает валюту bitcoin и точки за два часа: по три точки в часе
+func seedPrices(t *testing.T, db *sql.DB) {
+	t.Helper()
+	if _, err := db.Exec("INSERT INTO currencies (symbol) VALUES ('bitcoin')"); err != nil {
+		t.Fatal(err)
+	}
+	prices := []struct {
+		timestamp int64
+		price     string
+	}{
+		{3600, "65000.123456789012"}, {4000, "65100.5"}, {7199, "64900.000000000001"},
+		{7200, "0.000000001234"}, {8000, "70000"}, {10000, "69999.99"},
+	}
+	for _, p := range prices {
+		_, err := db.Exec(
+			"INSERT INTO currency_prices (currency_id, price, timestamp) SELECT id, $2, $3 FROM currencies WHERE symbol = $1",
+			"bitcoin", p.price, p.timestamp,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestTimescaleMigrations проверяет перевод существующей таблицы с данными в гипертаблицу,
+// свечи на NUMERIC и работу хранилища поверх гипертаблицы.
+// Нужен Postgres с расширением timescaledb, например из docker-compose.timescale.yml.
+func TestTimescaleMigrations(t *testing.T) {
+	db := storetest.OpenPostgres(t, storetest.TimescaleDSNEnv)
+	log := quietLogger()
+	if err := migrations.Postgres.Migrate(db, log); err != nil {
+		t.Fatal(err)
+	}
+	seedPrices(t, db)
+
+	enabled, err := migrations.MakeTimescaleMigrations(db, log, migrations.TimescaleOn)
+	if err != nil || !enabled {
+		t.Fatalf("MakeTimescaleMigrations = %v, %v", enabled, err)
+	}
+
+	var hypertables int
+	err = db.QueryRow(
+		`SELECT count(*) FROM timescaledb_information.hypertables
+		 WHERE hypertable_name = 'currency_prices' AND hypertable_schema = current_schema()`,
+	).Scan(&hypertables)
+	if err != nil || hypertables != 1 {
+		t.Fatalf("currency_prices is not a hypertable: %d, %v", hypertables, err)
+	}
+	var pkeys int
+	err = db.QueryRow(
+		"SELECT count(*) FROM pg_constraint WHERE conname = 'currency_prices_pkey' AND conrelid = 'currency_prices'::regclass",
+	).Scan(&pkeys)
+	if err != nil || pkeys != 0 {
+		t.Fatalf("currency_prices_pkey still exists: %d, %v", pkeys, err)
+	}
+	var rows int
+	if err := db.QueryRow("SELECT count(*) FROM currency_prices").Scan(&rows); err != nil || rows != 6 {
+		t.Fatalf("migrate_data kept %d rows, want 6 (%v)", rows, err)
+	}
+
+	// Upsert по UNIQUE(currency_id, timestamp) и поиск ближайшей точки работают на гипертаблице
+	store := sqlstore.New(db, 5*time.Second)
+	ctx := context.Background()
+	if err := store.Currency().UpdatePrice(ctx, "bitcoin", model.NewDecimal(6510055, 2), 4000, "test"); err != nil {
+		t.Fatal(err)
+	}
+	point, err := store.Currency().GetNearestPrice(ctx, "bitcoin", 4100)
+	if err != nil || point.Timestamp != 4000 || point.Price.String() != "65100.55" {
+		t.Fatalf("GetNearestPrice = %+v, %v", point, err)
+	}
+
+	if _, err := db.Exec("CALL refresh_continuous_aggregate('currency_candles_1h', NULL, NULL)"); err != nil {
+		t.Fatal(err)
+	}
+	candles, err := db.Query(
+		`SELECT bucket, open::text, high::text, low::text, close::text, samples
+		 FROM currency_candles_1h ORDER BY bucket`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer candles.Close()
+	want := [][6]any{
+		{int64(3600), "65000.123456789012", "65100.55", "64900.000000000001", "64900.000000000001", int64(3)},
+		{int64(7200), "0.000000001234", "70000", "0.000000001234", "69999.99", int64(3)},
+	}
+	i := 0
+	for ; candles.Next(); i++ {
+		var got [6]any
+		var bucket, samples int64
+		var open, high, low, close string
+		if err := candles.Scan(&bucket, &open, &high, &low, &close, &samples); err != nil {
+			t.Fatal(err)
+		}
+		got = [6]any{bucket, open, high, low, close, samples}
+		if i >= len(want) || got != want[i] {
+			t.Fatalf("candle %d = %v, want %v", i, got, want)
+		}
+	}
+	if err := candles.Err(); err != nil || i != len(want) {
+		t.Fatalf("got %d candles, want %d (%v)", i, len(want), err)
+	}
+
+	// Повторный запуск ничего не меняет
+	if enabled, err := migrations.MakeTimescaleMigrations(db, log, migrations.TimescaleOn); err != nil || !enabled {
+		t.Fatalf("second MakeTimescaleMigrations = %v, %v", enabled, err)
+	}
+}
+
+// TestTimescaleOff проверяет, что без TimescaleDB остается обычная таблица
+func TestTimescaleOff(t *testing.T) {
+	db := storetest.OpenPostgres(t, storetest.PostgresDSNEnv)
+	log := quietLogger()
+	if err := migrations.Postgres.Migrate(db, log); err != nil {
+		t.Fatal(err)
+	}
+	enabled, err := migrations.MakeTimescaleMigrations(db, log, migrations.TimescaleOff)
+	if err != nil || enabled {
+		t.Fatalf("MakeTimescaleMigrations(off) = %v, %v", enabled, err)
+	}
+	var pkeys int
+	err = db.QueryRow(
+		"SELECT count(*) FROM pg_constraint WHERE conname = 'currency_prices_pkey' AND conrelid = 'currency_prices'::regclass",
+	).Scan(&pkeys)
+	if err != nil || pkeys != 1 {
+		t.Fatalf("currency_prices_pkey = %d, %v; plain table must keep it", pkeys, err)
+	}
+}
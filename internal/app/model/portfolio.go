@@ -0,0 +1,95 @@
+package model
+
+// PricePoint - цена валюты в момент Timestamp.
+// Нулевой Timestamp означает, что точки нет.
+type PricePoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Price     Decimal `json:"price" swaggertype:"string"`
+}
+
+// Portfolio - набор позиций пользователя
+type Portfolio struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt int64      `json:"created_at"`
+	Positions []Position `json:"positions"`
+}
+
+// Position - покупка количества Quantity валюты CoinID.
+// CostBasis - полная стоимость покупки, а не цена за единицу.
+type Position struct {
+	ID         int64   `json:"id"`
+	CoinID     string  `json:"coin_id"`
+	Quantity   Decimal `json:"quantity" swaggertype:"string"`
+	CostBasis  Decimal `json:"cost_basis" swaggertype:"string"`
+	AcquiredAt int64   `json:"acquired_at"`
+}
+
+// PositionValuation - оценка позиции на момент At.
+// Если цены валюты на этот момент нет, Price равен nil, а позиция не входит в итоги.
+type PositionValuation struct {
+	Position
+	Price      *PricePoint `json:"price"`
+	Value      *Decimal    `json:"value" swaggertype:"string"`
+	PnL        *Decimal    `json:"pnl" swaggertype:"string"`
+	PnLPercent *Decimal    `json:"pnl_percent" swaggertype:"string"`
+	Allocation *Decimal    `json:"allocation_percent" swaggertype:"string"`
+}
+
+// Valuation - оценка портфеля на момент At.
+// Позиции, купленные после At, не учитываются.
+type Valuation struct {
+	PortfolioID int64               `json:"portfolio_id"`
+	At          int64               `json:"at"`
+	TotalValue  Decimal             `json:"total_value" swaggertype:"string"`
+	TotalCost   Decimal             `json:"total_cost" swaggertype:"string"`
+	PnL         Decimal             `json:"pnl" swaggertype:"string"`
+	PnLPercent  *Decimal            `json:"pnl_percent" swaggertype:"string"`
+	Positions   []PositionValuation `json:"positions"`
+	Unpriced    []string            `json:"unpriced"` // Валюты без цены на момент At
+}
+
+// Value оценивает портфель по ценам prices (coin id -> последняя точка не позже at)
+func (p Portfolio) Value(at int64, prices map[string]PricePoint) Valuation {
+	valuation := Valuation{
+		PortfolioID: p.ID,
+		At:          at,
+		Positions:   []PositionValuation{},
+		Unpriced:    []string{},
+	}
+	unpriced := make(map[string]bool)
+	for _, position := range p.Positions {
+		if position.AcquiredAt > at {
+			continue
+		}
+		pv := PositionValuation{Position: position}
+		price, ok := prices[position.CoinID]
+		if !ok || price.Timestamp == 0 {
+			if !unpriced[position.CoinID] {
+				unpriced[position.CoinID] = true
+				valuation.Unpriced = append(valuation.Unpriced, position.CoinID)
+			}
+			valuation.Positions = append(valuation.Positions, pv)
+			continue
+		}
+
+		value := position.Quantity.Mul(price.Price).Round(DefaultScale)
+		pnl := value.Sub(position.CostBasis)
+		pv.Price = &price
+		pv.Value = &value
+		pv.PnL = &pnl
+		pv.PnLPercent = PercentOf(pnl, position.CostBasis)
+		valuation.TotalValue = valuation.TotalValue.Add(value)
+		valuation.TotalCost = valuation.TotalCost.Add(position.CostBasis)
+		valuation.Positions = append(valuation.Positions, pv)
+	}
+
+	valuation.PnL = valuation.TotalValue.Sub(valuation.TotalCost)
+	valuation.PnLPercent = PercentOf(valuation.PnL, valuation.TotalCost)
+	for i := range valuation.Positions {
+		if value := valuation.Positions[i].Value; value != nil {
+			valuation.Positions[i].Allocation = PercentOf(*value, valuation.TotalValue)
+		}
+	}
+	return valuation
+}
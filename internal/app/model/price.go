@@ -1,57 +1,258 @@
 package model
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"math"
 	"math/big"
 	"strconv"
 	"strings"
 )
 
+// DefaultScale - число знаков после запятой по умолчанию (точность котировок CoinGecko)
+const DefaultScale int32 = 8
+
+// PercentScale - число знаков после запятой для процентов
+const PercentScale int32 = 4
+
+// Пределы разбора строки. Без них экспонента вида 1e50000000 заставляет
+// строить число из десятков миллионов цифр, а строка приходит из запроса.
+const (
+	MaxParseScale  = 64  // Максимальный модуль масштаба после учета экспоненты
+	MaxParseDigits = 256 // Максимум цифр мантиссы
+)
+
+var (
+	bigTen  = big.NewInt(10)
+	hundred = NewDecimal(100, 0)
+)
+
+// Decimal - десятичное число произвольной точности: value * 10^-scale.
+// Нулевое значение Decimal равно 0. Значения неизменяемы: все операции
+// возвращают новый Decimal.
 type Decimal struct {
-	IntPart  int64 `json:"int"`  // Целая часть
-	FracPart int64 `json:"frac"` // Дробная часть (всегда 8 знаков)
+	value *big.Int // Мантисса, nil означает 0
+	scale int32    // Число знаков после запятой
 }
 
-func (d *Decimal) UnmarshalJSON(data []byte) error {
-	s := strings.Trim(string(data), `"`)
+// NewDecimal создает число value * 10^-scale
+func NewDecimal(value int64, scale int32) Decimal {
+	return Decimal{value: big.NewInt(value), scale: scale}
+}
+
+// NewDecimalFromBigInt создает число value * 10^-scale из big.Int
+func NewDecimalFromBigInt(value *big.Int, scale int32) Decimal {
+	return Decimal{value: new(big.Int).Set(value), scale: scale}
+}
+
+// ParseDecimal разбирает строку вида "-123.456" или "1.5e-9" без потери точности
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
 	if s == "" {
-		return nil
+		return Decimal{}, fmt.Errorf("empty decimal string")
 	}
 
-	if strings.ContainsAny(s, "eE") {
-		f, _, err := big.ParseFloat(s, 10, 64, big.ToNearestEven)
+	// Экспонента
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.ParseInt(s[i+1:], 10, 32)
 		if err != nil {
-			return fmt.Errorf("failed to parse scientific notation: %w", err)
+			return Decimal{}, fmt.Errorf("invalid exponent: %w", err)
 		}
+		s = s[:i]
+	}
 
-		s = f.Text('f', 8) // 8 знаков после запятой
+	// Знак допускается только в начале, дальше - только цифры и одна точка
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	intStr, fracStr, _ := strings.Cut(s, ".")
+	digits := intStr + fracStr
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", sign+s)
+	}
+	if len(digits) > MaxParseDigits {
+		return Decimal{}, fmt.Errorf("decimal has more than %d digits", MaxParseDigits)
+	}
+	scale := int64(len(fracStr)) - exp
+	if scale > MaxParseScale || scale < -MaxParseScale {
+		return Decimal{}, fmt.Errorf("decimal scale out of range [-%d, %d]: %q", MaxParseScale, MaxParseScale, s)
 	}
 
-	parts := strings.SplitN(s, ".", 2)
-	var err error
+	value, ok := new(big.Int).SetString(sign+digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", sign+s)
+	}
 
-	d.IntPart, err = strconv.ParseInt(parts[0], 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid integer part: %w", err)
+	d := Decimal{value: value, scale: int32(scale)}
+	if d.scale < 0 {
+		// Отрицательный масштаб переносим в мантиссу
+		d = d.rescale(0)
 	}
+	return d, nil
+}
 
-	if len(parts) == 2 {
-		fracStr := parts[1]
+// NewDecimalFromFloat конвертирует float64 в Decimal с кратчайшим точным представлением
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("cannot convert %v to decimal", f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
 
-		if len(fracStr) < 8 {
-			fracStr += strings.Repeat("0", 8-len(fracStr))
-		} else if len(fracStr) > 8 {
-			fracStr = fracStr[:8]
-		}
+func (d Decimal) bigValue() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return d.value
+}
 
-		d.FracPart, err = strconv.ParseInt(fracStr, 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid fraction part: %w", err)
-		}
+// Scale возвращает число знаков после запятой
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// rescale приводит число к большему масштабу без потери точности
+func (d Decimal) rescale(scale int32) Decimal {
+	if scale == d.scale {
+		return d
+	}
+	if scale < d.scale {
+		return d.Round(scale)
+	}
+	factor := new(big.Int).Exp(bigTen, big.NewInt(int64(scale-d.scale)), nil)
+	return Decimal{value: new(big.Int).Mul(d.bigValue(), factor), scale: scale}
+}
+
+// align приводит два числа к общему масштабу
+func align(a, b Decimal) (Decimal, Decimal) {
+	if a.scale > b.scale {
+		return a, b.rescale(a.scale)
+	}
+	return a.rescale(b.scale), b
+}
+
+// Add возвращает d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{value: new(big.Int).Add(a.bigValue(), b.bigValue()), scale: a.scale}
+}
+
+// Sub возвращает d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{value: new(big.Int).Sub(a.bigValue(), b.bigValue()), scale: a.scale}
+}
+
+// Mul возвращает d * other; масштаб результата - сумма масштабов
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.bigValue(), other.bigValue()), scale: d.scale + other.scale}
+}
+
+// Div возвращает d / other, округленное до scale знаков.
+// Как и big.Int, паникует при делении на ноль.
+func (d Decimal) Div(other Decimal, scale int32) Decimal {
+	if other.IsZero() {
+		panic("model: decimal division by zero")
+	}
+	// d/other = (dv * 10^(scale + os - ds + 1)) / ov * 10^-(scale+1), лишний знак - для округления
+	shift := int64(scale) + int64(other.scale) - int64(d.scale) + 1
+	num := new(big.Int).Set(d.bigValue())
+	den := new(big.Int).Set(other.bigValue())
+	if shift >= 0 {
+		num.Mul(num, new(big.Int).Exp(bigTen, big.NewInt(shift), nil))
 	} else {
-		d.FracPart = 0
+		den.Mul(den, new(big.Int).Exp(bigTen, big.NewInt(-shift), nil))
+	}
+	quo := new(big.Int).Quo(num, den)
+	return Decimal{value: quo, scale: scale + 1}.Round(scale)
+}
+
+// Round округляет число до scale знаков, половину - от нуля
+func (d Decimal) Round(scale int32) Decimal {
+	if scale >= d.scale {
+		return d.rescale(scale)
+	}
+	factor := new(big.Int).Exp(bigTen, big.NewInt(int64(d.scale-scale)), nil)
+	quo, rem := new(big.Int).QuoRem(d.bigValue(), factor, new(big.Int))
+	// |rem| * 2 >= factor - округляем от нуля
+	if rem.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(factor) >= 0 {
+		quo.Add(quo, big.NewInt(int64(rem.Sign())))
+	}
+	return Decimal{value: quo, scale: scale}
+}
+
+// Cmp сравнивает числа: -1 если d < other, 0 если равны, +1 если d > other
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := align(d, other)
+	return a.bigValue().Cmp(b.bigValue())
+}
+
+// Sign возвращает -1, 0 или +1
+func (d Decimal) Sign() int {
+	return d.bigValue().Sign()
+}
+
+// Neg возвращает -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.bigValue()), scale: d.scale}
+}
+
+// Abs возвращает |d|
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.bigValue()), scale: d.scale}
+}
+
+// Float64 возвращает ближайшее значение float64, для статистики и индикаторов
+func (d Decimal) Float64() float64 {
+	r := new(big.Rat).SetInt(d.bigValue())
+	if d.scale >= 0 {
+		r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(bigTen, big.NewInt(int64(d.scale)), nil)))
+	} else {
+		// Отрицательный масштаб: value * 10^-scale, например после Round(-2)
+		r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(bigTen, big.NewInt(-int64(d.scale)), nil)))
+	}
+	f, _ := r.Float64()
+	return f
+}
+
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.bigValue()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
 	}
+	if d.scale <= 0 {
+		if d.IsZero() {
+			return s
+		}
+		// Отрицательный масштаб дописывается нулями: 12 при scale -2 - это 1200
+		return sign + s + strings.Repeat("0", -int(d.scale))
+	}
+	if len(s) <= int(d.scale) {
+		s = strings.Repeat("0", int(d.scale)-len(s)+1) + s
+	}
+	point := len(s) - int(d.scale)
+	return sign + s[:point] + "." + s[point:]
+}
 
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*d = Decimal{}
+		return nil
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
 	return nil
 }
 
@@ -59,11 +260,36 @@ func (d Decimal) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + d.String() + `"`), nil
 }
 
-func (d Decimal) String() string {
-	fracStr := fmt.Sprintf("%08d", d.FracPart)
-	return fmt.Sprintf("%d.%s", d.IntPart, fracStr)
+// Scan реализует sql.Scanner для колонок NUMERIC
+func (d *Decimal) Scan(src any) error {
+	var err error
+	switch v := src.(type) {
+	case nil:
+		*d = Decimal{}
+	case []byte:
+		*d, err = ParseDecimal(string(v))
+	case string:
+		*d, err = ParseDecimal(v)
+	case int64:
+		*d = NewDecimal(v, 0)
+	case float64:
+		*d, err = NewDecimalFromFloat(v)
+	default:
+		err = fmt.Errorf("cannot scan %T into Decimal", src)
+	}
+	return err
 }
 
-func (d Decimal) IsZero() bool {
-	return d.IntPart == 0 && d.FracPart == 0
+// Value реализует driver.Valuer: число передается в БД строкой без потери точности
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// PercentOf возвращает part / total * 100 или nil, если total равен нулю
+func PercentOf(part, total Decimal) *Decimal {
+	if total.IsZero() {
+		return nil
+	}
+	percent := part.Mul(hundred).Div(total, PercentScale)
+	return &percent
 }
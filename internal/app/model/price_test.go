@@ -0,0 +1,240 @@
+package model
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+var decimalSeeds = []string{
+	"0", "-0", "1", "-1", "0.5", "-0.5", "123.456", "-123.456", "1.5e-9", "2E10", "+7.25",
+	"0.00000001", "99999999999999999999.99999999", "-.5", "5.", "1e+3", "0012.3400",
+}
+
+// rat возвращает точное значение d
+func rat(d Decimal) *big.Rat {
+	r := new(big.Rat).SetInt(d.bigValue())
+	scale := new(big.Int).Exp(bigTen, big.NewInt(int64(abs32(d.scale))), nil)
+	if d.scale >= 0 {
+		return r.Quo(r, new(big.Rat).SetInt(scale))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(scale))
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// roundRat округляет r до scale знаков, половину - от нуля, как Decimal.Round.
+// Отрицательный scale округляет до десятков, сотен и т.д.
+func roundRat(r *big.Rat, scale int32) *big.Rat {
+	factor := new(big.Rat).SetInt(new(big.Int).Exp(bigTen, big.NewInt(int64(abs32(scale))), nil))
+	if scale < 0 {
+		factor.Inv(factor)
+	}
+	scaled := new(big.Rat).Mul(r, factor)
+	quo, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(scaled.Sign())))
+	}
+	return new(big.Rat).Quo(new(big.Rat).SetInt(quo), factor)
+}
+
+// checkRendering сверяет String, JSON, Value и Float64 числа d с его точным значением
+func checkRendering(t *testing.T, d Decimal) {
+	t.Helper()
+	want := rat(d)
+	parsed, ok := new(big.Rat).SetString(d.String())
+	if !ok || parsed.Cmp(want) != 0 {
+		t.Fatalf("String() = %q (scale %d), want %s", d.String(), d.Scale(), want.RatString())
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Decimal
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Cmp(d) != 0 {
+		t.Fatalf("JSON round trip of %s (scale %d): %s -> %s (%v)", want.RatString(), d.Scale(), data, decoded, err)
+	}
+	if value, err := d.Value(); err != nil || value != d.String() {
+		t.Fatalf("Value() = %v, %v; want %q", value, err, d.String())
+	}
+	if got, exact := d.Float64(), want; got != ratFloat(exact) {
+		t.Fatalf("Float64() of %s (scale %d) = %v, want %v", want.RatString(), d.Scale(), got, ratFloat(exact))
+	}
+}
+
+func ratFloat(r *big.Rat) float64 {
+	f, _ := r.Float64()
+	return f
+}
+
+func FuzzParseDecimal(f *testing.F) {
+	for _, seed := range decimalSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseDecimal(s)
+		if err != nil {
+			return
+		}
+		want, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+		if !ok {
+			t.Fatalf("ParseDecimal(%q) = %s, but math/big rejects the input", s, d)
+		}
+		if rat(d).Cmp(want) != 0 {
+			t.Fatalf("ParseDecimal(%q) = %s, want %s", s, d, want.RatString())
+		}
+
+		// Строка и JSON разбираются обратно в то же число с тем же масштабом
+		back, err := ParseDecimal(d.String())
+		if err != nil || back.Cmp(d) != 0 || back.Scale() != d.Scale() {
+			t.Fatalf("String round trip of %q: %s -> %s (%v)", s, d, back, err)
+		}
+		data, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Decimal
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded.Cmp(d) != 0 {
+			t.Fatalf("JSON round trip of %q: %s -> %s (%v)", s, data, decoded, err)
+		}
+	})
+}
+
+func FuzzDecimalArithmetic(f *testing.F) {
+	for i, a := range decimalSeeds {
+		f.Add(a, decimalSeeds[(i+3)%len(decimalSeeds)])
+	}
+	f.Fuzz(func(t *testing.T, as, bs string) {
+		a, errA := ParseDecimal(as)
+		b, errB := ParseDecimal(bs)
+		if errA != nil || errB != nil {
+			return
+		}
+		ra, rb := rat(a), rat(b)
+
+		if got, want := rat(a.Add(b)), new(big.Rat).Add(ra, rb); got.Cmp(want) != 0 {
+			t.Fatalf("%s + %s = %s, want %s", a, b, a.Add(b), want.RatString())
+		}
+		if got, want := rat(a.Sub(b)), new(big.Rat).Sub(ra, rb); got.Cmp(want) != 0 {
+			t.Fatalf("%s - %s = %s, want %s", a, b, a.Sub(b), want.RatString())
+		}
+		if got, want := rat(a.Mul(b)), new(big.Rat).Mul(ra, rb); got.Cmp(want) != 0 {
+			t.Fatalf("%s * %s = %s, want %s", a, b, a.Mul(b), want.RatString())
+		}
+		if got, want := a.Cmp(b), ra.Cmp(rb); got != want {
+			t.Fatalf("Cmp(%s, %s) = %d, want %d", a, b, got, want)
+		}
+		for _, scale := range []int32{DefaultScale, 0, -2, -5} {
+			rounded := a.Round(scale)
+			if got, want := rat(rounded), roundRat(ra, scale); got.Cmp(want) != 0 {
+				t.Fatalf("Round(%s, %d) = %s, want %s", a, scale, rounded, want.RatString())
+			}
+			checkRendering(t, rounded)
+			// Произведение округленных до отрицательного масштаба тоже имеет отрицательный масштаб
+			checkRendering(t, rounded.Mul(b.Round(scale)))
+		}
+		if !b.IsZero() {
+			quo := a.Div(b, DefaultScale)
+			if got, want := rat(quo), roundRat(new(big.Rat).Quo(ra, rb), DefaultScale); got.Cmp(want) != 0 {
+				t.Fatalf("%s / %s = %s, want %s", a, b, quo, want.RatString())
+			}
+		}
+	})
+}
+
+// FuzzDecimalNegativeScale проверяет числа с отрицательным масштабом: value * 10^-scale
+func FuzzDecimalNegativeScale(f *testing.F) {
+	for _, seed := range []struct {
+		value int64
+		scale int8
+	}{{5, -2}, {-7, -1}, {0, -3}, {12, -64}, {123456789, 3}, {-1, 0}} {
+		f.Add(seed.value, seed.scale)
+	}
+	f.Fuzz(func(t *testing.T, value int64, scale int8) {
+		// Строку с большим масштабом ParseDecimal отклоняет, JSON не разберется обратно
+		if abs32(int32(scale)) > MaxParseScale {
+			return
+		}
+		d := NewDecimal(value, int32(scale))
+		checkRendering(t, d)
+		checkRendering(t, d.Add(NewDecimal(1, 0)))
+		if got, want := rat(d.Round(-3)), roundRat(rat(d), -3); got.Cmp(want) != 0 {
+			t.Fatalf("Round(%s, -3) = %s, want %s", d, d.Round(-3), want.RatString())
+		}
+	})
+}
+
+func TestDecimalNegativeScale(t *testing.T) {
+	rounded := mustParse(t, "1234.5678").Round(-2)
+	tests := []struct {
+		d    Decimal
+		want string
+	}{
+		{NewDecimal(5, -2), "500"},
+		{NewDecimal(-7, -1), "-70"},
+		{NewDecimal(0, -3), "0"},
+		{rounded, "1200"},
+		{mustParse(t, "-1250").Round(-2), "-1300"},
+		{NewDecimalFromBigInt(big.NewInt(3), -4), "30000"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		checkRendering(t, tt.d)
+	}
+	if rounded.Float64() != 1200 || rounded.Cmp(NewDecimal(1200, 0)) != 0 {
+		t.Errorf("Round(-2): Float64 = %v, Cmp(1200) = %d", rounded.Float64(), rounded.Cmp(NewDecimal(1200, 0)))
+	}
+}
+
+func mustParse(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := ParseDecimal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestParseDecimalRejectsHugeScale(t *testing.T) {
+	inputs := []string{
+		"1e50000000", "1e-2000000000", "1e65", "1e-65",
+		"0." + strings.Repeat("0", 64) + "1",
+		strings.Repeat("9", MaxParseDigits+1),
+	}
+	for _, s := range inputs {
+		started := time.Now()
+		if d, err := ParseDecimal(s); err == nil {
+			t.Errorf("ParseDecimal(%.20q) = %.20s, want error", s, d)
+		}
+		if elapsed := time.Since(started); elapsed > 100*time.Millisecond {
+			t.Errorf("ParseDecimal(%.20q) took %v", s, elapsed)
+		}
+	}
+	for _, s := range []string{"1e64", "1e-64", strings.Repeat("9", MaxParseDigits)} {
+		if _, err := ParseDecimal(s); err != nil {
+			t.Errorf("ParseDecimal(%.20q): %v", s, err)
+		}
+	}
+}
+
+func TestDecimalNegativeBelowOne(t *testing.T) {
+	d, err := ParseDecimal("-0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.String() != "-0.5" || d.Sign() != -1 {
+		t.Fatalf("got %s", d)
+	}
+	if got := d.Round(0).String(); got != "-1" {
+		t.Fatalf("Round(-0.5, 0) = %s, want -1", got)
+	}
+}
@@ -0,0 +1,13 @@
+package model
+
+// QuarantinedPrice - входящая цена, отклоненная проверкой аномалий.
+// Лежит в карантине, пока ее не примут или не удалят вручную.
+type QuarantinedPrice struct {
+	ID        int64   `json:"id"`
+	CoinID    string  `json:"coin_id"`
+	Price     Decimal `json:"price" swaggertype:"string"`
+	Timestamp int64   `json:"timestamp"`
+	Reason    string  `json:"reason"` // non_positive, jump или stale
+	Detail    string  `json:"detail"`
+	CreatedAt int64   `json:"created_at"`
+}
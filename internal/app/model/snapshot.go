@@ -0,0 +1,15 @@
+package model
+
+// MarketSnapshot - рыночные данные валюты на момент опроса.
+// Поля, которые провайдер не вернул, равны nil.
+type MarketSnapshot struct {
+	Timestamp                int64    `json:"timestamp"`
+	Price                    Decimal  `json:"price" swaggertype:"string"`
+	MarketCap                *Decimal `json:"market_cap" swaggertype:"string"`
+	TotalVolume              *Decimal `json:"total_volume" swaggertype:"string"`
+	High24h                  *Decimal `json:"high_24h" swaggertype:"string"`
+	Low24h                   *Decimal `json:"low_24h" swaggertype:"string"`
+	PriceChangePercentage24h *Decimal `json:"price_change_percentage_24h" swaggertype:"string"`
+	CirculatingSupply        *Decimal `json:"circulating_supply" swaggertype:"string"`
+	ATH                      *Decimal `json:"ath" swaggertype:"string"`
+}
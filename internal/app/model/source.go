@@ -0,0 +1,19 @@
+package model
+
+// Источники цены в currency_prices помимо самих провайдеров
+const (
+	SourceConsensus  = "consensus"  // Сводная цена нескольких провайдеров
+	SourceQuarantine = "quarantine" // Цена принята из карантина вручную
+)
+
+// SourcePrice - цена валюты от одного провайдера в режиме консенсуса.
+// DeviationPercent - отклонение от итоговой цены Consensus, Flagged - отклонение больше порога.
+type SourcePrice struct {
+	CoinID           string  `json:"coin_id"`
+	Source           string  `json:"source"`
+	Price            Decimal `json:"price" swaggertype:"string"`
+	Consensus        Decimal `json:"consensus" swaggertype:"string"`
+	DeviationPercent Decimal `json:"deviation_percent" swaggertype:"string"`
+	Flagged          bool    `json:"flagged"`
+	Timestamp        int64   `json:"timestamp"`
+}
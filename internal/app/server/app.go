@@ -2,39 +2,227 @@ package server
 
 import (
 	"context"
+	"cryptoObserver/internal/app/anomaly"
 	coingecko "cryptoObserver/internal/app/coingeko"
 	"cryptoObserver/internal/app/migrations"
+	"cryptoObserver/internal/app/store/memstore"
+	"cryptoObserver/internal/app/store/pricecache"
+	"cryptoObserver/internal/app/store/sqlitestore"
 	"cryptoObserver/internal/app/store/sqlstore"
 	worker "cryptoObserver/internal/app/workers"
 	"database/sql"
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"time"
 )
 
 func Start(ctx context.Context, config *Config) (*App, error) {
-	db, err := newDB(config.GetDBConnectionString())
+	logger := logrus.New()
+	store, db, err := newStore(ctx, config, logger)
 	if err != nil {
 		return nil, err
 	}
-	store := sqlstore.New(db)
-	logger := logrus.New()
-	migrations.MakeMigrations(db, logger)
-	cryptoAPI := coingecko.NewCoinGeckoClient(config.CryptoAPI.Token)
-	pool := worker.NewWorkerPool(ctx, cryptoAPI, store, config.WorkerPool.Size, time.Duration(config.WorkerPool.UpdateTime)*time.Second, logger)
+	// Кеш id валют буфера записи нужно сбрасывать по событиям пула, до обертки кешем цен
+	currencyCache, _ := store.(worker.CurrencyCache)
+	if config.PriceCache.Freshness > 0 {
+		store = pricecache.New(store, time.Duration(config.PriceCache.Freshness)*time.Second)
+	}
+	sources := newSources(config)
+	pool := worker.NewWorkerPool(ctx, sources[0], store,
+		config.WorkerPool.Size,
+		time.Duration(config.WorkerPool.UpdateTime)*time.Second,
+		time.Duration(config.WorkerPool.ReconcileTime)*time.Second,
+		logger,
+	)
+	if currencyCache != nil {
+		pool.SetCurrencyCache(currencyCache)
+	}
+	if config.Anomaly.Enabled {
+		pool.SetValidator(anomaly.NewValidator(anomaly.Rules{
+			RejectNonPositive: config.Anomaly.RejectNonPositive,
+			MaxJumpPercent:    config.Anomaly.MaxJumpPercent,
+			MedianWindow:      config.Anomaly.MedianWindow,
+			RejectStale:       config.Anomaly.RejectStale,
+		}))
+	}
+	if len(sources) > 1 {
+		switch config.Sources.Mode {
+		case "failover":
+			pool.SetFailover(sources, config.Sources.FailoverThreshold,
+				time.Duration(config.Sources.FailoverProbeInterval)*time.Second)
+		default:
+			pool.SetConsensus(sources, config.Sources.Method, config.Sources.MaxDivergence)
+		}
+	}
+	// Координация реплик и LISTEN/NOTIFY есть только у Postgres
+	if config.Database.Driver == DriverPostgres {
+		coordinationInterval := time.Duration(config.Coordination.Interval) * time.Second
+		switch config.Coordination.Mode {
+		case "leader":
+			pool.SetOwnership(worker.NewLeaderElector(db, coordinationInterval, logger))
+		case "shard":
+			pool.SetOwnership(worker.NewShardMembership(db, config.Coordination.MemberID, coordinationInterval, logger))
+		}
+		if err := pool.Listen(config.GetDBConnectionString()); err != nil {
+			logger.Errorf("Failed to subscribe to currency changes, relying on periodic reconcile: %v", err)
+		}
+	}
 	defer pool.Start()
 	srv := newApp(ctx, store, *config, logger, pool)
 	return srv, nil
 }
 
-func newDB(dbURL string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", dbURL)
+// newSources создает клиентов провайдеров из PRICE_SOURCES в заданном порядке
+func newSources(config *Config) []worker.Source {
+	sources := make([]worker.Source, 0, len(config.Sources.Names))
+	for _, name := range config.Sources.Names {
+		var client coingecko.CryptoInterface
+		switch name {
+		case SourceCoinCap:
+			client = coingecko.NewCoinCapClient(config.CoinCap.Token)
+		default:
+			client = coingecko.NewCoinGeckoClient(config.CryptoAPI.Token)
+		}
+		sources = append(sources, worker.Source{Name: name, Client: client})
+	}
+	return sources
+}
+
+// newStore открывает хранилище, выбранное в DB_DRIVER, и готовит схему
+func newStore(ctx context.Context, config *Config, logger *logrus.Logger) (sqlstore.StoreInterface, *sql.DB, error) {
+	queryTimeout := time.Duration(config.Database.QueryTimeout) * time.Second
+	if config.Database.Driver == DriverMemory {
+		logger.Warn("Using in-memory storage, data will be lost on restart")
+		return memstore.New(), nil, nil
+	}
+
+	db, set, err := OpenDatabase(ctx, config, logger)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if err := prepareSchema(db, set, config, logger); err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+	if config.Database.Driver == DriverSQLite {
+		return sqlitestore.New(db, queryTimeout), db, nil
 	}
 
-	if err := db.Ping(); err != nil {
+	store := sqlstore.New(db, queryTimeout)
+	if config.Database.WriteBatchSize > 0 {
+		store.EnableWriteBuffer(
+			config.Database.WriteBatchSize,
+			time.Duration(config.Database.WriteFlushTimeout)*time.Millisecond,
+			logger,
+		)
+	}
+	return store, db, nil
+}
+
+// OpenDatabase подключается к БД из DB_DRIVER и возвращает набор миграций для нее.
+// Используется сервером и командой migrate.
+func OpenDatabase(ctx context.Context, config *Config, logger *logrus.Logger) (*sql.DB, migrations.Set, error) {
+	switch config.Database.Driver {
+	case DriverSQLite:
+		db, err := sqlitestore.Open(config.Database.Path)
+		return db, migrations.SQLite, err
+	case DriverPostgres:
+		db, err := newDB(ctx, config, logger)
+		return db, migrations.Postgres, err
+	default:
+		return nil, migrations.Set{}, fmt.Errorf("driver %q has no database schema", config.Database.Driver)
+	}
+}
+
+// prepareSchema применяет миграции, если включен DB_AUTO_MIGRATE, и не дает
+// запустить сервер на схеме, отстающей от встроенных миграций.
+// Для Postgres с включенной TimescaleDB то же относится к набору TimescaleDB.
+// DB_SKIP_SCHEMA_CHECK отключает проверку: сервер стартует с предупреждением.
+func prepareSchema(db *sql.DB, set migrations.Set, config *Config, logger *logrus.Logger) error {
+	var err error
+	if config.Database.AutoMigrate {
+		err = set.Migrate(db, logger)
+	}
+	if err == nil {
+		err = set.CheckVersion(db)
+	}
+	if err == nil && set.Name == migrations.Postgres.Name {
+		err = prepareTimescale(db, config, logger)
+	}
+	if err != nil {
+		if !config.Database.SkipSchemaCheck {
+			return fmt.Errorf("schema check failed: %w", err)
+		}
+		logger.Warnf("Schema check failed, starting anyway because DB_SKIP_SCHEMA_CHECK is set: %v", err)
+	}
+	return nil
+}
+
+// prepareTimescale применяет миграции TimescaleDB только при DB_AUTO_MIGRATE
+// и проверяет их версию, если TimescaleDB включена в DB_TIMESCALE.
+// В режиме auto неудачное включение оставляет обычные таблицы без проверки.
+func prepareTimescale(db *sql.DB, config *Config, logger *logrus.Logger) error {
+	mode := config.Database.Timescale
+	var enabled bool
+	var err error
+	if config.Database.AutoMigrate {
+		enabled, err = migrations.MakeTimescaleMigrations(db, logger, mode)
+	} else {
+		enabled, err = migrations.TimescaleEnabled(db, logger, mode)
+	}
+	if err != nil || !enabled {
+		return err
+	}
+	if mode == migrations.TimescaleAuto && !config.Database.AutoMigrate {
+		// Если при прошлом запуске с DB_AUTO_MIGRATE включить TimescaleDB не удалось,
+		// сервис работал на обычных таблицах. Без миграций продолжаем так же
+		current, _, err := migrations.Timescale.Version(db)
+		if err != nil {
+			return err
+		}
+		if current == 0 {
+			logger.Warn("TimescaleDB migrations are not applied, using plain tables")
+			return nil
+		}
+	}
+	return migrations.Timescale.CheckVersion(db)
+}
+
+// newDB открывает пул соединений с Postgres. Если БД еще не поднялась,
+// подключение повторяется с экспоненциальной паузой. После старта database/sql
+// сам переоткрывает соединения, оборванные перезапуском БД.
+func newDB(ctx context.Context, config *Config, logger *logrus.Logger) (*sql.DB, error) {
+	db, err := sql.Open("postgres", config.GetDBConnectionString())
+	if err != nil {
 		return nil, err
 	}
 
-	return db, nil
+	pool := config.Database.Pool
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(pool.ConnMaxLifetime) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(pool.ConnMaxIdleTime) * time.Second)
+
+	connect := config.Database.Connect
+	backoff := time.Duration(connect.BackoffMin) * time.Millisecond
+	maxBackoff := time.Duration(connect.BackoffMax) * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return db, nil
+		}
+		if attempt == connect.Retries {
+			break
+		}
+		logger.Warnf("Database is not available (attempt %d/%d), retrying in %v: %v", attempt, connect.Retries, backoff, err)
+		select {
+		case <-ctx.Done():
+			_ = db.Close()
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+
+	_ = db.Close()
+	return nil, fmt.Errorf("database is not available after %d attempts: %w", connect.Retries, err)
 }
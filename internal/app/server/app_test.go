@@ -0,0 +1,127 @@
+package server
+
+import (
+	"cryptoObserver/internal/app/migrations"
+	"cryptoObserver/internal/app/store/sqlitestore"
+	"cryptoObserver/internal/app/store/storetest"
+	"database/sql"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func quietLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func schemaConfig(autoMigrate bool, timescale string) *Config {
+	config := &Config{}
+	config.Database.AutoMigrate = autoMigrate
+	config.Database.Timescale = timescale
+	return config
+}
+
+func hypertables(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var count int
+	err := db.QueryRow(
+		`SELECT count(*) FROM timescaledb_information.hypertables
+		 WHERE hypertable_name = 'currency_prices' AND hypertable_schema = current_schema()`,
+	).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestPrepareSchemaSQLite(t *testing.T) {
+	db, err := sqlitestore.Open(filepath.Join(t.TempDir(), "observer.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	log := quietLogger()
+
+	if err := prepareSchema(db, migrations.SQLite, schemaConfig(false, migrations.TimescaleAuto), log); !errors.Is(err, migrations.ErrSchemaBehind) {
+		t.Fatalf("prepareSchema on empty database without auto-migrate = %v, want ErrSchemaBehind", err)
+	}
+	skip := schemaConfig(false, migrations.TimescaleAuto)
+	skip.Database.SkipSchemaCheck = true
+	if err := prepareSchema(db, migrations.SQLite, skip, log); err != nil {
+		t.Fatalf("prepareSchema with skipped check: %v", err)
+	}
+	if err := prepareSchema(db, migrations.SQLite, schemaConfig(true, migrations.TimescaleAuto), log); err != nil {
+		t.Fatalf("prepareSchema with auto-migrate: %v", err)
+	}
+}
+
+// TestPrepareSchemaTimescale проверяет, что без DB_AUTO_MIGRATE гипертаблица не создается,
+// а отставание набора TimescaleDB останавливает запуск
+func TestPrepareSchemaTimescale(t *testing.T) {
+	db := storetest.OpenPostgres(t, storetest.TimescaleDSNEnv)
+	log := quietLogger()
+	if err := migrations.Postgres.Migrate(db, log); err != nil {
+		t.Fatal(err)
+	}
+
+	err := prepareSchema(db, migrations.Postgres, schemaConfig(false, migrations.TimescaleOn), log)
+	if !errors.Is(err, migrations.ErrSchemaBehind) {
+		t.Fatalf("prepareSchema without auto-migrate = %v, want ErrSchemaBehind", err)
+	}
+	if count := hypertables(t, db); count != 0 {
+		t.Fatalf("timescale migrations ran without DB_AUTO_MIGRATE")
+	}
+
+	if err := prepareSchema(db, migrations.Postgres, schemaConfig(true, migrations.TimescaleOn), log); err != nil {
+		t.Fatalf("prepareSchema with auto-migrate: %v", err)
+	}
+	if count := hypertables(t, db); count != 1 {
+		t.Fatalf("currency_prices is not a hypertable after auto-migrate")
+	}
+	// Схема актуальна - запуск без миграций проходит проверку
+	if err := prepareSchema(db, migrations.Postgres, schemaConfig(false, migrations.TimescaleOn), log); err != nil {
+		t.Fatalf("prepareSchema on migrated schema: %v", err)
+	}
+}
+
+// TestPrepareSchemaTimescaleAuto проверяет, что в режиме auto непримененный набор TimescaleDB
+// (например, после неудачного включения) не останавливает запуск без DB_AUTO_MIGRATE
+func TestPrepareSchemaTimescaleAuto(t *testing.T) {
+	db := storetest.OpenPostgres(t, storetest.TimescaleDSNEnv)
+	log := quietLogger()
+	if err := migrations.Postgres.Migrate(db, log); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := prepareSchema(db, migrations.Postgres, schemaConfig(false, migrations.TimescaleAuto), log); err != nil {
+		t.Fatalf("prepareSchema in auto mode without timescale migrations: %v", err)
+	}
+	if count := hypertables(t, db); count != 0 {
+		t.Fatalf("timescale migrations ran without DB_AUTO_MIGRATE")
+	}
+
+	if err := prepareSchema(db, migrations.Postgres, schemaConfig(true, migrations.TimescaleAuto), log); err != nil {
+		t.Fatalf("prepareSchema with auto-migrate: %v", err)
+	}
+	if count := hypertables(t, db); count != 1 {
+		t.Fatalf("currency_prices is not a hypertable after auto-migrate")
+	}
+	if err := prepareSchema(db, migrations.Postgres, schemaConfig(false, migrations.TimescaleAuto), log); err != nil {
+		t.Fatalf("prepareSchema on migrated schema: %v", err)
+	}
+}
+
+func TestPrepareSchemaTimescaleOff(t *testing.T) {
+	db := storetest.OpenPostgres(t, storetest.PostgresDSNEnv)
+	log := quietLogger()
+	if err := migrations.Postgres.Migrate(db, log); err != nil {
+		t.Fatal(err)
+	}
+	if err := prepareSchema(db, migrations.Postgres, schemaConfig(false, migrations.TimescaleOff), log); err != nil {
+		t.Fatalf("prepareSchema with DB_TIMESCALE=off: %v", err)
+	}
+}
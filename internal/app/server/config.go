@@ -1,9 +1,24 @@
 package server
 
 import (
+	worker "cryptoObserver/internal/app/workers"
 	"log"
 	"os"
 	"strconv"
+	"strings"
+)
+
+// Поддерживаемые драйверы хранилища
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory" // Данные в памяти, без БД
+)
+
+// Поддерживаемые провайдеры цен
+const (
+	SourceCoinGecko = "coingecko"
+	SourceCoinCap   = "coincap"
 )
 
 type Config struct {
@@ -11,18 +26,73 @@ type Config struct {
 		Port string
 	}
 	Database struct {
-		Host     string
-		Port     string
-		Name     string
-		User     string
-		Password string
+		Driver            string // postgres, sqlite или memory
+		Path              string // Файл базы SQLite
+		Host              string
+		Port              string
+		Name              string
+		User              string
+		Password          string
+		Timescale         string // auto, on или off
+		QueryTimeout      int    // Таймаут одного запроса к БД, сек. 0 - без таймаута
+		WriteBatchSize    int    // Размер пакета записи цен, 0 - писать сразу
+		WriteFlushTimeout int    // Период сброса пакета цен, мс
+		AutoMigrate       bool   // Применять миграции при старте
+		SkipSchemaCheck   bool   // Запускаться, даже если схема отстает от миграций
+		SSL               struct {
+			Mode     string // disable, require, verify-ca или verify-full
+			RootCert string
+			Cert     string
+			Key      string
+		}
+		Pool struct {
+			MaxOpenConns    int
+			MaxIdleConns    int
+			ConnMaxLifetime int // сек
+			ConnMaxIdleTime int // сек
+		}
+		Connect struct {
+			Retries    int // Попыток подключения при старте
+			BackoffMin int // Начальная пауза между попытками, мс
+			BackoffMax int // Максимальная пауза между попытками, мс
+		}
+	}
+	PriceCache struct {
+		Freshness int // Окно свежести кеша последних цен, сек. 0 - кеш выключен
+	}
+	Anomaly struct {
+		Enabled           bool
+		RejectNonPositive bool
+		MaxJumpPercent    float64 // Допустимое отклонение от скользящей медианы, %. 0 - без проверки
+		MedianWindow      int     // Число последних точек в медиане
+		RejectStale       bool    // Отклонять повтор цены без обновления у провайдера
+	}
+	Convert struct {
+		MaxSkew int // Допустимый разрыв во времени между ценами двух валют, сек
 	}
 	CryptoAPI struct {
 		Token string
 	}
+	CoinCap struct {
+		Token string
+	}
+	Sources struct {
+		Names                 []string // Провайдеры цен по порядку: coingecko, coincap
+		Mode                  string   // consensus или failover, если провайдеров больше одного
+		Method                string   // median или trimmed_mean
+		MaxDivergence         float64  // Порог отклонения провайдера от консенсуса, %
+		FailoverThreshold     int      // Ошибок основного провайдера подряд до переключения
+		FailoverProbeInterval int      // Период проверки основного провайдера после переключения, сек
+	}
 	WorkerPool struct {
-		Size       int
-		UpdateTime int
+		Size          int
+		UpdateTime    int
+		ReconcileTime int
+	}
+	Coordination struct {
+		Mode     string // none, leader или shard
+		Interval int
+		MemberID string // Идентификатор реплики для шардирования
 	}
 }
 
@@ -33,26 +103,158 @@ func LoadConfig() *Config {
 	cfg.Server.Port = getEnv("SERVER_PORT", "8080")
 
 	// Database
+	cfg.Database.Driver = getEnv("DB_DRIVER", DriverPostgres)
+	cfg.Database.Path = getEnv("DB_PATH", "cryptoObserver.db")
 	cfg.Database.Host = getEnv("DB_HOST", "localhost")
 	cfg.Database.Port = getEnv("DB_PORT", "5432")
 	cfg.Database.Name = getEnv("DB_NAME", "crypto")
 	cfg.Database.User = getEnv("DB_USER", "postgres")
 	cfg.Database.Password = getEnv("DB_PASSWORD", "")
+	cfg.Database.Timescale = getEnv("DB_TIMESCALE", "auto")
+	cfg.Database.SSL.Mode = getEnv("DB_SSLMODE", "disable")
+	cfg.Database.SSL.RootCert = getEnv("DB_SSLROOTCERT", "")
+	cfg.Database.SSL.Cert = getEnv("DB_SSLCERT", "")
+	cfg.Database.SSL.Key = getEnv("DB_SSLKEY", "")
+	cfg.Database.Pool.MaxOpenConns, _ = strconv.Atoi(getEnv("DB_MAX_OPEN_CONNS", "25"))
+	cfg.Database.Pool.MaxIdleConns, _ = strconv.Atoi(getEnv("DB_MAX_IDLE_CONNS", "10"))
+	cfg.Database.Pool.ConnMaxLifetime, _ = strconv.Atoi(getEnv("DB_CONN_MAX_LIFETIME", "1800"))
+	cfg.Database.Pool.ConnMaxIdleTime, _ = strconv.Atoi(getEnv("DB_CONN_MAX_IDLE_TIME", "300"))
+	cfg.Database.Connect.Retries, _ = strconv.Atoi(getEnv("DB_CONNECT_RETRIES", "10"))
+	cfg.Database.Connect.BackoffMin, _ = strconv.Atoi(getEnv("DB_CONNECT_BACKOFF_MIN_MS", "500"))
+	cfg.Database.Connect.BackoffMax, _ = strconv.Atoi(getEnv("DB_CONNECT_BACKOFF_MAX_MS", "30000"))
+	cfg.Database.QueryTimeout, _ = strconv.Atoi(getEnv("DB_QUERY_TIMEOUT", "5"))
+	cfg.Database.WriteBatchSize, _ = strconv.Atoi(getEnv("DB_WRITE_BATCH_SIZE", "500"))
+	cfg.Database.WriteFlushTimeout, _ = strconv.Atoi(getEnv("DB_WRITE_FLUSH_MS", "1000"))
+	cfg.Database.AutoMigrate, _ = strconv.ParseBool(getEnv("DB_AUTO_MIGRATE", "true"))
+	cfg.Database.SkipSchemaCheck, _ = strconv.ParseBool(getEnv("DB_SKIP_SCHEMA_CHECK", "false"))
+
+	// PriceCache
+	cfg.PriceCache.Freshness, _ = strconv.Atoi(getEnv("PRICE_CACHE_FRESHNESS", "120"))
+
+	// Anomaly
+	cfg.Anomaly.Enabled, _ = strconv.ParseBool(getEnv("ANOMALY_DETECTION", "true"))
+	cfg.Anomaly.RejectNonPositive, _ = strconv.ParseBool(getEnv("ANOMALY_REJECT_NON_POSITIVE", "true"))
+	cfg.Anomaly.MaxJumpPercent, _ = strconv.ParseFloat(getEnv("ANOMALY_MAX_JUMP_PERCENT", "50"), 64)
+	cfg.Anomaly.MedianWindow, _ = strconv.Atoi(getEnv("ANOMALY_MEDIAN_WINDOW", "10"))
+	cfg.Anomaly.RejectStale, _ = strconv.ParseBool(getEnv("ANOMALY_REJECT_STALE", "false"))
+
+	// Convert
+	cfg.Convert.MaxSkew, _ = strconv.Atoi(getEnv("CONVERT_MAX_SKEW", "300"))
 
 	// CryptoAPI
 	cfg.CryptoAPI.Token = getEnv("CRYPTO_API_KEY", "")
+	cfg.CoinCap.Token = getEnv("COINCAP_API_KEY", "")
+
+	// Sources
+	for _, name := range strings.Split(getEnv("PRICE_SOURCES", SourceCoinGecko), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.Sources.Names = append(cfg.Sources.Names, name)
+		}
+	}
+	cfg.Sources.Mode = getEnv("PRICE_SOURCE_MODE", "consensus")
+	cfg.Sources.Method = getEnv("CONSENSUS_METHOD", worker.ConsensusMedian)
+	cfg.Sources.MaxDivergence, _ = strconv.ParseFloat(getEnv("CONSENSUS_MAX_DIVERGENCE_PERCENT", "2"), 64)
+	cfg.Sources.FailoverThreshold, _ = strconv.Atoi(getEnv("FAILOVER_THRESHOLD", "3"))
+	cfg.Sources.FailoverProbeInterval, _ = strconv.Atoi(getEnv("FAILOVER_PROBE_INTERVAL", "300"))
 
 	// WorkerPool
 	cfg.WorkerPool.Size, _ = strconv.Atoi(getEnv("WORKER_POOL_SIZE", "10"))
 	cfg.WorkerPool.UpdateTime, _ = strconv.Atoi(getEnv("WORKER_POOL_UPDATE_TIME", "60"))
+	cfg.WorkerPool.ReconcileTime, _ = strconv.Atoi(getEnv("WORKER_POOL_RECONCILE_TIME", "30"))
+
+	// Coordination
+	cfg.Coordination.Mode = getEnv("WORKER_COORDINATION", "none")
+	cfg.Coordination.Interval, _ = strconv.Atoi(getEnv("WORKER_COORDINATION_INTERVAL", "10"))
+	cfg.Coordination.MemberID = getEnv("WORKER_MEMBER_ID", defaultMemberID())
 
 	// Validate
-	if cfg.Database.Password == "" {
-		log.Fatal("DB_PASSWORD is required")
+	switch cfg.Database.Driver {
+	case DriverPostgres:
+		if cfg.Database.Password == "" {
+			log.Fatal("DB_PASSWORD is required")
+		}
+	case DriverSQLite, DriverMemory:
+		if cfg.Coordination.Mode != "none" {
+			log.Fatal("WORKER_COORDINATION requires DB_DRIVER=postgres")
+		}
+	default:
+		log.Fatal("DB_DRIVER must be one of: postgres, sqlite, memory")
 	}
 	if cfg.WorkerPool.Size == 0 || cfg.WorkerPool.UpdateTime == 0 {
 		log.Fatal("WORKER_POOL_SIZE and WORKER_POOL_UPDATE_TIME must be int and greater than 0")
 	}
+	if cfg.WorkerPool.ReconcileTime <= 0 {
+		log.Fatal("WORKER_POOL_RECONCILE_TIME must be int and greater than 0")
+	}
+	if cfg.Database.WriteBatchSize > 0 && cfg.Database.WriteFlushTimeout <= 0 {
+		log.Fatal("DB_WRITE_FLUSH_MS must be int and greater than 0")
+	}
+	switch cfg.Database.SSL.Mode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		log.Fatal("DB_SSLMODE must be one of: disable, require, verify-ca, verify-full")
+	}
+	if cfg.Database.Pool.MaxOpenConns < 0 || cfg.Database.Pool.MaxIdleConns < 0 {
+		log.Fatal("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must be int and not negative")
+	}
+	if cfg.Database.Connect.Retries < 1 || cfg.Database.Connect.BackoffMin <= 0 ||
+		cfg.Database.Connect.BackoffMax < cfg.Database.Connect.BackoffMin {
+		log.Fatal("DB_CONNECT_RETRIES must be at least 1, DB_CONNECT_BACKOFF_MAX_MS must not be less than DB_CONNECT_BACKOFF_MIN_MS")
+	}
+	if cfg.Anomaly.MaxJumpPercent < 0 || cfg.Anomaly.MedianWindow < 1 {
+		log.Fatal("ANOMALY_MAX_JUMP_PERCENT must not be negative, ANOMALY_MEDIAN_WINDOW must be int and greater than 0")
+	}
+	if cfg.Convert.MaxSkew < 0 {
+		log.Fatal("CONVERT_MAX_SKEW must be int and not negative")
+	}
+	if len(cfg.Sources.Names) == 0 {
+		log.Fatal("PRICE_SOURCES must not be empty")
+	}
+	seen := make(map[string]bool)
+	for _, name := range cfg.Sources.Names {
+		switch name {
+		case SourceCoinGecko, SourceCoinCap:
+		default:
+			log.Fatal("PRICE_SOURCES must be a comma-separated list of: coingecko, coincap")
+		}
+		if seen[name] {
+			log.Fatalf("PRICE_SOURCES lists %s twice", name)
+		}
+		seen[name] = true
+	}
+	switch cfg.Sources.Mode {
+	case "consensus":
+	case "failover":
+		if len(cfg.Sources.Names) < 2 {
+			log.Fatal("PRICE_SOURCE_MODE=failover requires at least two PRICE_SOURCES")
+		}
+	default:
+		log.Fatal("PRICE_SOURCE_MODE must be one of: consensus, failover")
+	}
+	if cfg.Sources.FailoverThreshold < 1 || cfg.Sources.FailoverProbeInterval <= 0 {
+		log.Fatal("FAILOVER_THRESHOLD must be at least 1, FAILOVER_PROBE_INTERVAL must be int and greater than 0")
+	}
+	switch cfg.Sources.Method {
+	case worker.ConsensusMedian, worker.ConsensusTrimmedMean:
+	default:
+		log.Fatal("CONSENSUS_METHOD must be one of: median, trimmed_mean")
+	}
+	if cfg.Sources.MaxDivergence <= 0 {
+		log.Fatal("CONSENSUS_MAX_DIVERGENCE_PERCENT must be greater than 0")
+	}
+	switch cfg.Database.Timescale {
+	case "auto", "on", "off":
+	default:
+		log.Fatal("DB_TIMESCALE must be one of: auto, on, off")
+	}
+	switch cfg.Coordination.Mode {
+	case "none", "leader", "shard":
+	default:
+		log.Fatal("WORKER_COORDINATION must be one of: none, leader, shard")
+	}
+	if cfg.Coordination.Interval <= 0 {
+		log.Fatal("WORKER_COORDINATION_INTERVAL must be int and greater than 0")
+	}
 	return &cfg
 }
 
@@ -64,12 +266,41 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// defaultMemberID возвращает уникальный в пределах кластера id реплики
+func defaultMemberID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return hostname + "-" + strconv.Itoa(os.Getpid())
+}
+
 // GetDBConnectionString возвращает строку подключения к PostgreSQL
 func (c *Config) GetDBConnectionString() string {
-	return "host=" + c.Database.Host +
-		" port=" + c.Database.Port +
-		" dbname=" + c.Database.Name +
-		" user=" + c.Database.User +
-		" password=" + c.Database.Password +
-		" sslmode=disable"
+	params := []string{
+		"host=" + quoteConnValue(c.Database.Host),
+		"port=" + quoteConnValue(c.Database.Port),
+		"dbname=" + quoteConnValue(c.Database.Name),
+		"user=" + quoteConnValue(c.Database.User),
+		"password=" + quoteConnValue(c.Database.Password),
+		"sslmode=" + quoteConnValue(c.Database.SSL.Mode),
+	}
+	if c.Database.SSL.RootCert != "" {
+		params = append(params, "sslrootcert="+quoteConnValue(c.Database.SSL.RootCert))
+	}
+	if c.Database.SSL.Cert != "" {
+		params = append(params, "sslcert="+quoteConnValue(c.Database.SSL.Cert))
+	}
+	if c.Database.SSL.Key != "" {
+		params = append(params, "sslkey="+quoteConnValue(c.Database.SSL.Key))
+	}
+	return strings.Join(params, " ")
+}
+
+// quoteConnValue экранирует значение для строки подключения вида key=value,
+// чтобы пароль с пробелами или кавычками не ломал ее
+func quoteConnValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "cryptoObserver/docs"
 	"cryptoObserver/internal/app/handlers"
+	"cryptoObserver/internal/app/store/pricecache"
 	"cryptoObserver/internal/app/store/sqlstore"
 	worker "cryptoObserver/internal/app/workers"
 	"fmt"
@@ -62,7 +63,29 @@ func (a *App) configureRouter() {
 		r.Post("/add", handlers.NewAddCurrencyHandler(a.logger, a.store.Currency(), a.pool))
 		r.Delete("/remove", handlers.NewRemoveCurrencyHandler(a.logger, a.store.Currency(), a.pool))
 		r.Post("/price", handlers.NewGetPriceHandler(a.logger, a.store.Currency()))
+		r.Get("/snapshots", handlers.NewGetSnapshotsHandler(a.logger, a.store.Market()))
+		r.Get("/{id}/indicators", handlers.NewGetIndicatorsHandler(a.logger, a.store.Currency()))
+		r.Get("/{id}/stats", handlers.NewGetStatsHandler(a.logger, a.store.Currency()))
+		r.Get("/{id}/sources", handlers.NewGetSourcePricesHandler(a.logger, a.store.Source()))
 	})
+	a.router.Route("/portfolios", func(r chi.Router) {
+		r.Post("/", handlers.NewCreatePortfolioHandler(a.logger, a.store.Portfolio()))
+		r.Get("/{id}", handlers.NewGetPortfolioHandler(a.logger, a.store.Portfolio()))
+		r.Post("/{id}/positions", handlers.NewAddPositionHandler(a.logger, a.store.Portfolio(), a.store.Currency(), a.pool))
+		r.Get("/{id}/valuation", handlers.NewGetValuationHandler(a.logger, a.store.Portfolio(), a.store.Currency()))
+		r.Get("/{id}/performance", handlers.NewGetPerformanceHandler(a.logger, a.store.Portfolio(), a.store.Currency()))
+	})
+	a.router.Route("/quarantine", func(r chi.Router) {
+		r.Get("/", handlers.NewListQuarantinedHandler(a.logger, a.store.Quarantine()))
+		r.Post("/{id}/accept", handlers.NewAcceptQuarantinedHandler(a.logger, a.store.Quarantine(), a.store.Currency(), a.pool))
+		r.Delete("/{id}", handlers.NewDeleteQuarantinedHandler(a.logger, a.store.Quarantine()))
+	})
+	a.router.Route("/analytics", func(r chi.Router) {
+		r.Get("/correlation", handlers.NewGetCorrelationHandler(a.logger, a.store.Currency()))
+	})
+	a.router.Get("/convert", handlers.NewConvertHandler(a.logger, a.store.Currency(), time.Duration(a.config.Convert.MaxSkew)*time.Second))
+	cache, _ := a.store.(*pricecache.Store)
+	a.router.Get("/metrics", handlers.NewMetricsHandler(cache))
 	a.router.Get("/api/doc/*", httpSwagger.WrapHandler)
 }
 
@@ -79,7 +102,7 @@ func (a *App) logRequest(next http.Handler) http.Handler {
 		start := time.Now()
 		rw := &responseWriter{w, http.StatusOK}
 		next.ServeHTTP(rw, r)
-		if strings.HasPrefix(r.Method, "/metrics") {
+		if strings.HasPrefix(r.URL.Path, "/metrics") {
 			return
 		}
 		logger.Infof("started %s %s", r.Method, r.RequestURI)
@@ -127,11 +150,13 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	a.router.ServeHTTP(w, r)
 }
 
+// Close останавливает сервер и пул воркеров, затем закрывает хранилище,
+// чтобы буферизованные цены успели записаться
 func (a *App) Close() error {
-	defer a.pool.Stop()
 	err := a.Server.Close()
-	if err != nil {
-		return err
+	a.pool.Stop()
+	if storeErr := a.store.Close(); storeErr != nil && err == nil {
+		err = storeErr
 	}
-	return a.Server.Close()
+	return err
 }
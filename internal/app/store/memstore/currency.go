@@ -0,0 +1,127 @@
+package memstore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"database/sql"
+	"sort"
+)
+
+type CurrencyRepository struct {
+	store *Store
+}
+
+func (r *CurrencyRepository) AddCurrency(ctx context.Context, coin string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.currencies[coin]; !exists {
+		r.store.currencies[coin] = &currency{}
+	}
+	return nil
+}
+
+func (r *CurrencyRepository) RemoveCurrency(ctx context.Context, coin string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Как ON DELETE CASCADE: цены и снимки удаляются вместе с валютой
+	delete(r.store.currencies, coin)
+	return nil
+}
+
+func (r *CurrencyRepository) GetPrice(ctx context.Context, coin string, timestamp int64) (model.Decimal, error) {
+	point, err := r.GetNearestPrice(ctx, coin, timestamp)
+	return point.Price, err
+}
+
+func (r *CurrencyRepository) GetNearestPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	c, exists := r.store.currencies[coin]
+	if !exists || len(c.prices) == 0 {
+		return model.PricePoint{}, nil
+	}
+
+	// Ближайшая точка - первая не раньше timestamp или предыдущая; при равенстве - более ранняя
+	i := sort.Search(len(c.prices), func(i int) bool { return c.prices[i].timestamp >= timestamp })
+	nearest := c.prices[0]
+	switch {
+	case i == len(c.prices):
+		nearest = c.prices[i-1]
+	case i > 0:
+		before, after := c.prices[i-1], c.prices[i]
+		nearest = after
+		if timestamp-before.timestamp <= after.timestamp-timestamp {
+			nearest = before
+		}
+	}
+	return model.PricePoint{Timestamp: nearest.timestamp, Price: nearest.price}, nil
+}
+
+func (r *CurrencyRepository) GetLastPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	c, exists := r.store.currencies[coin]
+	if !exists {
+		return model.PricePoint{}, nil
+	}
+	// Первая точка позже timestamp, нужная - перед ней
+	i := sort.Search(len(c.prices), func(i int) bool { return c.prices[i].timestamp > timestamp })
+	if i == 0 {
+		return model.PricePoint{}, nil
+	}
+	return model.PricePoint{Timestamp: c.prices[i-1].timestamp, Price: c.prices[i-1].price}, nil
+}
+
+func (r *CurrencyRepository) GetPriceSeries(ctx context.Context, coin string, from, to int64) ([]model.PricePoint, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	series := []model.PricePoint{}
+	c, exists := r.store.currencies[coin]
+	if !exists {
+		return series, nil
+	}
+	i := sort.Search(len(c.prices), func(i int) bool { return c.prices[i].timestamp >= from })
+	for ; i < len(c.prices) && c.prices[i].timestamp <= to; i++ {
+		series = append(series, model.PricePoint{Timestamp: c.prices[i].timestamp, Price: c.prices[i].price})
+	}
+	return series, nil
+}
+
+func (r *CurrencyRepository) GetCurrencyList(ctx context.Context) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var currencies []string
+	for coin := range r.store.currencies {
+		currencies = append(currencies, coin)
+	}
+	return currencies, nil
+}
+
+func (r *CurrencyRepository) UpdatePrice(ctx context.Context, coin string, price model.Decimal, timestamp int64, source string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, exists := r.store.currencies[coin]
+	if !exists {
+		// Как и SELECT id в Postgres-хранилище
+		return sql.ErrNoRows
+	}
+
+	// Вставляем или обновляем цену, сохраняя порядок по timestamp
+	i := sort.Search(len(c.prices), func(i int) bool { return c.prices[i].timestamp >= timestamp })
+	if i < len(c.prices) && c.prices[i].timestamp == timestamp {
+		c.prices[i].price = price
+		c.prices[i].source = source
+		return nil
+	}
+	c.prices = append(c.prices, pricePoint{})
+	copy(c.prices[i+1:], c.prices[i:])
+	c.prices[i] = pricePoint{timestamp: timestamp, price: price, source: source}
+	return nil
+}
@@ -0,0 +1,52 @@
+package memstore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"sort"
+)
+
+type MarketRepository struct {
+	store *Store
+}
+
+func (r *MarketRepository) SaveSnapshot(ctx context.Context, coin string, snapshot model.MarketSnapshot) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Снимок неотслеживаемой валюты игнорируется, как INSERT ... SELECT в Postgres
+	c, exists := r.store.currencies[coin]
+	if !exists {
+		return nil
+	}
+
+	i := sort.Search(len(c.snapshots), func(i int) bool { return c.snapshots[i].Timestamp >= snapshot.Timestamp })
+	if i < len(c.snapshots) && c.snapshots[i].Timestamp == snapshot.Timestamp {
+		c.snapshots[i] = snapshot
+		return nil
+	}
+	c.snapshots = append(c.snapshots, model.MarketSnapshot{})
+	copy(c.snapshots[i+1:], c.snapshots[i:])
+	c.snapshots[i] = snapshot
+	return nil
+}
+
+func (r *MarketRepository) GetSnapshots(ctx context.Context, coin string, from, to int64) ([]model.MarketSnapshot, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	snapshots := []model.MarketSnapshot{}
+	c, exists := r.store.currencies[coin]
+	if !exists {
+		return snapshots, nil
+	}
+
+	start := sort.Search(len(c.snapshots), func(i int) bool { return c.snapshots[i].Timestamp >= from })
+	for _, s := range c.snapshots[start:] {
+		if s.Timestamp > to {
+			break
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
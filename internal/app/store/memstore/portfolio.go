@@ -0,0 +1,65 @@
+package memstore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+type PortfolioRepository struct {
+	store *Store
+}
+
+func (r *PortfolioRepository) CreatePortfolio(ctx context.Context, name string) (model.Portfolio, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastPortfolioID++
+	portfolio := &model.Portfolio{
+		ID:        r.store.lastPortfolioID,
+		Name:      name,
+		CreatedAt: time.Now().Unix(),
+	}
+	r.store.portfolios[portfolio.ID] = portfolio
+	return copyPortfolio(portfolio), nil
+}
+
+func (r *PortfolioRepository) GetPortfolio(ctx context.Context, id int64) (model.Portfolio, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	portfolio, exists := r.store.portfolios[id]
+	if !exists {
+		return model.Portfolio{}, sql.ErrNoRows
+	}
+	return copyPortfolio(portfolio), nil
+}
+
+func (r *PortfolioRepository) AddPosition(ctx context.Context, portfolioID int64, position model.Position) (model.Position, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	portfolio, exists := r.store.portfolios[portfolioID]
+	if !exists {
+		return model.Position{}, sql.ErrNoRows
+	}
+	r.store.lastPositionID++
+	position.ID = r.store.lastPositionID
+	// Порядок как в ORDER BY acquired_at, id
+	i := sort.Search(len(portfolio.Positions), func(i int) bool {
+		return portfolio.Positions[i].AcquiredAt > position.AcquiredAt
+	})
+	portfolio.Positions = append(portfolio.Positions, model.Position{})
+	copy(portfolio.Positions[i+1:], portfolio.Positions[i:])
+	portfolio.Positions[i] = position
+	return position, nil
+}
+
+// copyPortfolio возвращает копию, которую вызывающий может менять без блокировки
+func copyPortfolio(portfolio *model.Portfolio) model.Portfolio {
+	result := *portfolio
+	result.Positions = append([]model.Position{}, portfolio.Positions...)
+	return result
+}
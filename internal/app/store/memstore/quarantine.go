@@ -0,0 +1,61 @@
+package memstore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"database/sql"
+	"sort"
+)
+
+type QuarantineRepository struct {
+	store *Store
+}
+
+func (r *QuarantineRepository) AddQuarantined(ctx context.Context, price model.QuarantinedPrice) (model.QuarantinedPrice, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastQuarantinedID++
+	price.ID = r.store.lastQuarantinedID
+	r.store.quarantined[price.ID] = price
+	return price, nil
+}
+
+func (r *QuarantineRepository) ListQuarantined(ctx context.Context, coin string) ([]model.QuarantinedPrice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	prices := []model.QuarantinedPrice{}
+	for _, price := range r.store.quarantined {
+		if coin == "" || price.CoinID == coin {
+			prices = append(prices, price)
+		}
+	}
+	// Порядок как в ORDER BY timestamp, id
+	sort.Slice(prices, func(i, j int) bool {
+		if prices[i].Timestamp != prices[j].Timestamp {
+			return prices[i].Timestamp < prices[j].Timestamp
+		}
+		return prices[i].ID < prices[j].ID
+	})
+	return prices, nil
+}
+
+func (r *QuarantineRepository) GetQuarantined(ctx context.Context, id int64) (model.QuarantinedPrice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	price, exists := r.store.quarantined[id]
+	if !exists {
+		return model.QuarantinedPrice{}, sql.ErrNoRows
+	}
+	return price, nil
+}
+
+func (r *QuarantineRepository) DeleteQuarantined(ctx context.Context, id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.quarantined, id)
+	return nil
+}
@@ -0,0 +1,41 @@
+package memstore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"sort"
+)
+
+type SourceRepository struct {
+	store *Store
+}
+
+func (r *SourceRepository) SaveSourcePrices(ctx context.Context, prices []model.SourcePrice) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, price := range prices {
+		r.store.sourcePrices[price.CoinID] = append(r.store.sourcePrices[price.CoinID], price)
+	}
+	return nil
+}
+
+func (r *SourceRepository) GetSourcePrices(ctx context.Context, coin string, from, to int64) ([]model.SourcePrice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	prices := []model.SourcePrice{}
+	for _, price := range r.store.sourcePrices[coin] {
+		if price.Timestamp >= from && price.Timestamp <= to {
+			prices = append(prices, price)
+		}
+	}
+	// Порядок как в ORDER BY timestamp, source
+	sort.SliceStable(prices, func(i, j int) bool {
+		if prices[i].Timestamp != prices[j].Timestamp {
+			return prices[i].Timestamp < prices[j].Timestamp
+		}
+		return prices[i].Source < prices[j].Source
+	})
+	return prices, nil
+}
@@ -0,0 +1,82 @@
+package memstore
+
+import (
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"sync"
+)
+
+// Store - потокобезопасная реализация sqlstore.StoreInterface в памяти.
+// Повторяет семантику Postgres-хранилища и подходит для тестов и демо без БД;
+// данные теряются при перезапуске.
+type Store struct {
+	mu         sync.RWMutex
+	currencies map[string]*currency
+
+	portfolios      map[int64]*model.Portfolio
+	lastPortfolioID int64
+	lastPositionID  int64
+
+	quarantined       map[int64]model.QuarantinedPrice
+	lastQuarantinedID int64
+
+	sourcePrices map[string][]model.SourcePrice // coin id -> цены провайдеров
+
+	currencyRepository   sqlstore.CurrencyInterface
+	marketRepository     sqlstore.MarketInterface
+	portfolioRepository  sqlstore.PortfolioInterface
+	quarantineRepository sqlstore.QuarantineInterface
+	sourceRepository     sqlstore.SourceInterface
+}
+
+// currency - данные одной валюты, точки упорядочены по timestamp
+type currency struct {
+	prices    []pricePoint
+	snapshots []model.MarketSnapshot
+}
+
+type pricePoint struct {
+	timestamp int64
+	price     model.Decimal
+	source    string // Провайдер, от которого получена цена
+}
+
+func New() *Store {
+	s := &Store{
+		currencies:   make(map[string]*currency),
+		portfolios:   make(map[int64]*model.Portfolio),
+		quarantined:  make(map[int64]model.QuarantinedPrice),
+		sourcePrices: make(map[string][]model.SourcePrice),
+	}
+	s.currencyRepository = &CurrencyRepository{store: s}
+	s.marketRepository = &MarketRepository{store: s}
+	s.portfolioRepository = &PortfolioRepository{store: s}
+	s.quarantineRepository = &QuarantineRepository{store: s}
+	s.sourceRepository = &SourceRepository{store: s}
+	return s
+}
+
+func (s *Store) Currency() sqlstore.CurrencyInterface {
+	return s.currencyRepository
+}
+
+func (s *Store) Market() sqlstore.MarketInterface {
+	return s.marketRepository
+}
+
+func (s *Store) Portfolio() sqlstore.PortfolioInterface {
+	return s.portfolioRepository
+}
+
+func (s *Store) Quarantine() sqlstore.QuarantineInterface {
+	return s.quarantineRepository
+}
+
+func (s *Store) Source() sqlstore.SourceInterface {
+	return s.sourceRepository
+}
+
+// Close ничего не делает: данным в памяти нечего сбрасывать
+func (s *Store) Close() error {
+	return nil
+}
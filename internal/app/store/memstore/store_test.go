@@ -0,0 +1,115 @@
+package memstore_test
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/memstore"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/storetest"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) sqlstore.StoreInterface {
+		return memstore.New()
+	})
+}
+
+// TestConcurrentAddRemove смешивает добавление, удаление, запись и чтение всех
+// репозиториев; смысл проверки - запуск с -race
+func TestConcurrentAddRemove(t *testing.T) {
+	const (
+		goroutines = 8
+		iterations = 200
+	)
+	ctx := context.Background()
+	store := memstore.New()
+	portfolio, err := store.Portfolio().CreatePortfolio(ctx, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			coin := fmt.Sprintf("coin-%d", g%3)
+			price := model.NewDecimal(int64(g+1), 0)
+			for i := 0; i < iterations; i++ {
+				ts := int64(i)
+				_ = store.Currency().AddCurrency(ctx, coin)
+				// Валюту могла удалить другая горутина, sql.ErrNoRows здесь ожидаем
+				_ = store.Currency().UpdatePrice(ctx, coin, price, ts, "test")
+				_ = store.Market().SaveSnapshot(ctx, coin, model.MarketSnapshot{Timestamp: ts, Price: price})
+				_, _ = store.Currency().GetNearestPrice(ctx, coin, ts)
+				_, _ = store.Currency().GetPriceSeries(ctx, coin, 0, ts)
+				_, _ = store.Market().GetSnapshots(ctx, coin, 0, ts)
+				_, _ = store.Currency().GetCurrencyList(ctx)
+				if _, err := store.Portfolio().AddPosition(ctx, portfolio.ID, model.Position{
+					CoinID: coin, Quantity: price, CostBasis: price, AcquiredAt: ts,
+				}); err != nil {
+					t.Error(err)
+				}
+				_, _ = store.Portfolio().GetPortfolio(ctx, portfolio.ID)
+				_, _ = store.Quarantine().AddQuarantined(ctx, model.QuarantinedPrice{CoinID: coin, Price: price, Timestamp: ts})
+				_, _ = store.Quarantine().ListQuarantined(ctx, coin)
+				_ = store.Source().SaveSourcePrices(ctx, []model.SourcePrice{{CoinID: coin, Source: "test", Price: price, Timestamp: ts}})
+				_, _ = store.Source().GetSourcePrices(ctx, coin, 0, ts)
+				if i%10 == 0 {
+					_ = store.Currency().RemoveCurrency(ctx, coin)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	got, err := store.Portfolio().GetPortfolio(ctx, portfolio.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Positions) != goroutines*iterations {
+		t.Fatalf("got %d positions, want %d", len(got.Positions), goroutines*iterations)
+	}
+}
+
+// TestReturnedDataIsCopied проверяет, что изменение результатов не меняет данные хранилища
+func TestReturnedDataIsCopied(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.New()
+	if err := store.Currency().AddCurrency(ctx, "bitcoin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Currency().UpdatePrice(ctx, "bitcoin", model.NewDecimal(1, 0), 100, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Market().SaveSnapshot(ctx, "bitcoin", model.MarketSnapshot{Timestamp: 100, Price: model.NewDecimal(1, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	portfolio, err := store.Portfolio().CreatePortfolio(ctx, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Portfolio().AddPosition(ctx, portfolio.ID, model.Position{CoinID: "bitcoin", AcquiredAt: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	series, _ := store.Currency().GetPriceSeries(ctx, "bitcoin", 0, 1000)
+	series[0].Timestamp = 999
+	snapshots, _ := store.Market().GetSnapshots(ctx, "bitcoin", 0, 1000)
+	snapshots[0].Timestamp = 999
+	got, _ := store.Portfolio().GetPortfolio(ctx, portfolio.ID)
+	got.Positions[0].CoinID = "changed"
+
+	if series, _ := store.Currency().GetPriceSeries(ctx, "bitcoin", 0, 1000); series[0].Timestamp != 100 {
+		t.Errorf("price series aliases store data")
+	}
+	if snapshots, _ := store.Market().GetSnapshots(ctx, "bitcoin", 0, 1000); snapshots[0].Timestamp != 100 {
+		t.Errorf("snapshots alias store data")
+	}
+	if got, _ := store.Portfolio().GetPortfolio(ctx, portfolio.ID); got.Positions[0].CoinID != "bitcoin" {
+		t.Errorf("portfolio positions alias store data")
+	}
+}
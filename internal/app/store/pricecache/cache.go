@@ -0,0 +1,129 @@
+package pricecache
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Store оборачивает хранилище кешем последних цен.
+// Остальные репозитории и Close проксируются без изменений.
+type Store struct {
+	sqlstore.StoreInterface
+	currency *CurrencyRepository
+}
+
+// New создает кеш с окном свежести freshness поверх store
+func New(store sqlstore.StoreInterface, freshness time.Duration) *Store {
+	return &Store{
+		StoreInterface: store,
+		currency: &CurrencyRepository{
+			CurrencyInterface: store.Currency(),
+			freshness:         freshness,
+			latest:            make(map[string]latestPrice),
+		},
+	}
+}
+
+func (s *Store) Currency() sqlstore.CurrencyInterface {
+	return s.currency
+}
+
+// Stats возвращает счетчики кеша
+func (s *Store) Stats() Stats {
+	return s.currency.Stats()
+}
+
+// Stats - метрики попаданий в кеш
+type Stats struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+	Entries  int     `json:"entries"`
+}
+
+// latestPrice - последняя записанная через этот процесс цена валюты
+type latestPrice struct {
+	price     model.Decimal
+	timestamp int64
+}
+
+// CurrencyRepository отвечает на запросы цены "на сейчас" из памяти.
+// Кеш наполняется при UpdatePrice из пула воркеров. Запрос обслуживается из кеша,
+// если запрошенный момент не раньше последней точки (значит, она и есть ближайшая),
+// а сама точка моложе окна свежести. Это окно ограничивает устаревание, когда
+// точки пишет другая реплика. Исторические запросы идут в БД.
+type CurrencyRepository struct {
+	sqlstore.CurrencyInterface
+	freshness time.Duration
+
+	mu     sync.RWMutex
+	latest map[string]latestPrice
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (r *CurrencyRepository) GetPrice(ctx context.Context, coin string, timestamp int64) (model.Decimal, error) {
+	point, err := r.GetNearestPrice(ctx, coin, timestamp)
+	return point.Price, err
+}
+
+func (r *CurrencyRepository) GetNearestPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error) {
+	r.mu.RLock()
+	entry, ok := r.latest[coin]
+	r.mu.RUnlock()
+
+	window := int64(r.freshness.Seconds())
+	if ok && timestamp >= entry.timestamp && time.Now().Unix()-entry.timestamp <= window {
+		r.hits.Add(1)
+		return model.PricePoint{Timestamp: entry.timestamp, Price: entry.price}, nil
+	}
+
+	r.misses.Add(1)
+	return r.CurrencyInterface.GetNearestPrice(ctx, coin, timestamp)
+}
+
+func (r *CurrencyRepository) UpdatePrice(ctx context.Context, coin string, price model.Decimal, timestamp int64, source string) error {
+	if err := r.CurrencyInterface.UpdatePrice(ctx, coin, price, timestamp, source); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.latest[coin]; !ok || timestamp >= entry.timestamp {
+		r.latest[coin] = latestPrice{price: price, timestamp: timestamp}
+	}
+	return nil
+}
+
+func (r *CurrencyRepository) RemoveCurrency(ctx context.Context, coin string) error {
+	if err := r.CurrencyInterface.RemoveCurrency(ctx, coin); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.latest, coin)
+	return nil
+}
+
+// Stats возвращает счетчики кеша
+func (r *CurrencyRepository) Stats() Stats {
+	r.mu.RLock()
+	entries := len(r.latest)
+	r.mu.RUnlock()
+
+	stats := Stats{
+		Hits:    r.hits.Load(),
+		Misses:  r.misses.Load(),
+		Entries: entries,
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
@@ -0,0 +1,96 @@
+package pricecache
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/memstore"
+	"testing"
+	"time"
+)
+
+func TestGetNearestPrice(t *testing.T) {
+	now := time.Now().Unix()
+	tests := []struct {
+		name   string
+		cached int64 // Время точки, записанной через кеш
+		query  int64
+		want   string // Цена 100 - из кеша, 999 и 50 - из хранилища
+		hits   uint64
+		misses uint64
+	}{
+		{"fresh", now - 10, now, "100", 1, 0},
+		{"exact timestamp", now - 10, now - 10, "100", 1, 0},
+		// Точка старше окна свежести: другая реплика могла записать более новую
+		{"stale", now - 3600, now, "999", 0, 1},
+		// Кеш знает только последнюю точку, ближайшая к прошлому моменту - в хранилище
+		{"historical", now - 10, now - 100, "50", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mem := memstore.New()
+			if err := mem.Currency().AddCurrency(ctx, "bitcoin"); err != nil {
+				t.Fatal(err)
+			}
+			cache := New(mem, time.Minute)
+			if err := cache.Currency().UpdatePrice(ctx, "bitcoin", model.NewDecimal(100, 0), tt.cached, "test"); err != nil {
+				t.Fatal(err)
+			}
+			// Точки, записанные мимо кеша, видны только при запросе в хранилище
+			if err := mem.Currency().UpdatePrice(ctx, "bitcoin", model.NewDecimal(999, 0), tt.cached, "test"); err != nil {
+				t.Fatal(err)
+			}
+			if err := mem.Currency().UpdatePrice(ctx, "bitcoin", model.NewDecimal(50, 0), min(tt.cached, tt.query)-50, "test"); err != nil {
+				t.Fatal(err)
+			}
+
+			point, err := cache.Currency().GetNearestPrice(ctx, "bitcoin", tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if point.Price.String() != tt.want {
+				t.Fatalf("price = %s, want %s", point.Price, tt.want)
+			}
+			stats := cache.Stats()
+			if stats.Hits != tt.hits || stats.Misses != tt.misses {
+				t.Fatalf("hits/misses = %d/%d, want %d/%d", stats.Hits, stats.Misses, tt.hits, tt.misses)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	mem := memstore.New()
+	cache := New(mem, time.Minute)
+	if stats := cache.Stats(); stats != (Stats{}) {
+		t.Fatalf("empty cache stats = %+v", stats)
+	}
+
+	now := time.Now().Unix()
+	for _, coin := range []string{"bitcoin", "ethereum"} {
+		if err := cache.Currency().AddCurrency(ctx, coin); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Currency().UpdatePrice(ctx, coin, model.NewDecimal(100, 0), now, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, timestamp := range []int64{now, now + 10, now + 20, now - 100} {
+		if _, err := cache.Currency().GetPrice(ctx, "bitcoin", timestamp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := Stats{Hits: 3, Misses: 1, HitRatio: 0.75, Entries: 2}
+	if stats := cache.Stats(); stats != want {
+		t.Fatalf("stats = %+v, want %+v", stats, want)
+	}
+
+	// Снятая с отслеживания валюта уходит из кеша
+	if err := cache.Currency().RemoveCurrency(ctx, "ethereum"); err != nil {
+		t.Fatal(err)
+	}
+	if entries := cache.Stats().Entries; entries != 1 {
+		t.Fatalf("entries after remove = %d, want 1", entries)
+	}
+}
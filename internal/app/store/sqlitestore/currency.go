@@ -0,0 +1,179 @@
+package sqlitestore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"database/sql"
+)
+
+type CurrencyRepository struct {
+	store *Store
+}
+
+func (r *CurrencyRepository) AddCurrency(ctx context.Context, currency string) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.db.ExecContext(ctx,
+		"INSERT INTO currencies (symbol) VALUES (?1) ON CONFLICT (symbol) DO NOTHING",
+		currency,
+	)
+	return err
+}
+
+func (r *CurrencyRepository) RemoveCurrency(ctx context.Context, currency string) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.db.ExecContext(ctx,
+		"DELETE FROM currencies WHERE symbol = ?1",
+		currency,
+	)
+	return err
+}
+
+func (r *CurrencyRepository) GetPrice(ctx context.Context, coin string, timestamp int64) (model.Decimal, error) {
+	point, err := r.GetNearestPrice(ctx, coin, timestamp)
+	return point.Price, err
+}
+
+func (r *CurrencyRepository) GetNearestPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var point model.PricePoint
+	// Как и в Postgres: два поиска по индексу (currency_id, timestamp) вместо сортировки всех точек
+	err := r.store.db.QueryRowContext(ctx,
+		`SELECT price, timestamp FROM (
+		     SELECT * FROM (
+		         SELECT price, timestamp
+		         FROM currency_prices
+		         WHERE currency_id = (SELECT id FROM currencies WHERE symbol = ?1) AND timestamp <= ?2
+		         ORDER BY timestamp DESC
+		         LIMIT 1
+		     )
+		     UNION ALL
+		     SELECT * FROM (
+		         SELECT price, timestamp
+		         FROM currency_prices
+		         WHERE currency_id = (SELECT id FROM currencies WHERE symbol = ?1) AND timestamp >= ?2
+		         ORDER BY timestamp ASC
+		         LIMIT 1
+		     )
+		 )
+		 ORDER BY ABS(timestamp - ?2), timestamp
+		 LIMIT 1`,
+		coin, timestamp,
+	).Scan(&point.Price, &point.Timestamp)
+	if err == sql.ErrNoRows {
+		return model.PricePoint{}, nil
+	}
+	if err != nil {
+		return model.PricePoint{}, err
+	}
+	return point, nil
+}
+
+func (r *CurrencyRepository) GetLastPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var point model.PricePoint
+	err := r.store.db.QueryRowContext(ctx,
+		`SELECT price, timestamp
+		 FROM currency_prices
+		 WHERE currency_id = (SELECT id FROM currencies WHERE symbol = ?1) AND timestamp <= ?2
+		 ORDER BY timestamp DESC
+		 LIMIT 1`,
+		coin, timestamp,
+	).Scan(&point.Price, &point.Timestamp)
+	if err == sql.ErrNoRows {
+		return model.PricePoint{}, nil
+	}
+	if err != nil {
+		return model.PricePoint{}, err
+	}
+	return point, nil
+}
+
+func (r *CurrencyRepository) GetPriceSeries(ctx context.Context, coin string, from, to int64) ([]model.PricePoint, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.db.QueryContext(ctx,
+		`SELECT price, timestamp
+		 FROM currency_prices
+		 WHERE currency_id = (SELECT id FROM currencies WHERE symbol = ?1) AND timestamp BETWEEN ?2 AND ?3
+		 ORDER BY timestamp`,
+		coin, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := []model.PricePoint{}
+	for rows.Next() {
+		var point model.PricePoint
+		if err := rows.Scan(&point.Price, &point.Timestamp); err != nil {
+			return nil, err
+		}
+		series = append(series, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (r *CurrencyRepository) GetCurrencyList(ctx context.Context) ([]string, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var currencies []string
+	rows, err := r.store.db.QueryContext(ctx, "SELECT symbol FROM currencies")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var currency string
+		if err := rows.Scan(&currency); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, currency)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return currencies, nil
+}
+
+func (r *CurrencyRepository) UpdatePrice(ctx context.Context, coin string, price model.Decimal, timestamp int64, source string) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	// Получаем id валюты по символу
+	var currencyID int
+	err := r.store.db.QueryRowContext(ctx,
+		"SELECT id FROM currencies WHERE symbol = ?1",
+		coin,
+	).Scan(&currencyID)
+	if err != nil {
+		return err
+	}
+
+	// Вставляем или обновляем цену
+	_, err = r.store.db.ExecContext(ctx,
+		`INSERT INTO currency_prices (currency_id, price, timestamp, source)
+		 VALUES (?1, ?2, ?3, ?4)
+		 ON CONFLICT (currency_id, timestamp) DO UPDATE SET price = excluded.price, source = excluded.source`,
+		currencyID, price, timestamp, source,
+	)
+	return err
+}
@@ -0,0 +1,89 @@
+package sqlitestore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"time"
+)
+
+type PortfolioRepository struct {
+	store *Store
+}
+
+func (r *PortfolioRepository) CreatePortfolio(ctx context.Context, name string) (model.Portfolio, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	portfolio := model.Portfolio{
+		Name:      name,
+		CreatedAt: time.Now().Unix(),
+		Positions: []model.Position{},
+	}
+	err := r.store.db.QueryRowContext(ctx,
+		"INSERT INTO portfolios (name, created_at) VALUES (?1, ?2) RETURNING id",
+		portfolio.Name, portfolio.CreatedAt,
+	).Scan(&portfolio.ID)
+	if err != nil {
+		return model.Portfolio{}, err
+	}
+	return portfolio, nil
+}
+
+func (r *PortfolioRepository) GetPortfolio(ctx context.Context, id int64) (model.Portfolio, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	portfolio := model.Portfolio{ID: id, Positions: []model.Position{}}
+	err := r.store.db.QueryRowContext(ctx,
+		"SELECT name, created_at FROM portfolios WHERE id = ?1",
+		id,
+	).Scan(&portfolio.Name, &portfolio.CreatedAt)
+	if err != nil {
+		return model.Portfolio{}, err
+	}
+
+	rows, err := r.store.db.QueryContext(ctx,
+		`SELECT id, coin_id, quantity, cost_basis, acquired_at
+		 FROM portfolio_positions
+		 WHERE portfolio_id = ?1
+		 ORDER BY acquired_at, id`,
+		id,
+	)
+	if err != nil {
+		return model.Portfolio{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p model.Position
+		if err := rows.Scan(&p.ID, &p.CoinID, &p.Quantity, &p.CostBasis, &p.AcquiredAt); err != nil {
+			return model.Portfolio{}, err
+		}
+		portfolio.Positions = append(portfolio.Positions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return model.Portfolio{}, err
+	}
+
+	return portfolio, nil
+}
+
+func (r *PortfolioRepository) AddPosition(ctx context.Context, portfolioID int64, position model.Position) (model.Position, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	// Вставка через SELECT ничего не добавит, если портфеля нет, и Scan вернет sql.ErrNoRows
+	err := r.store.db.QueryRowContext(ctx,
+		`INSERT INTO portfolio_positions (portfolio_id, coin_id, quantity, cost_basis, acquired_at)
+		 SELECT p.id, ?2, ?3, ?4, ?5
+		 FROM portfolios p
+		 WHERE p.id = ?1
+		 RETURNING id`,
+		portfolioID, position.CoinID, position.Quantity, position.CostBasis, position.AcquiredAt,
+	).Scan(&position.ID)
+	if err != nil {
+		return model.Position{}, err
+	}
+	return position, nil
+}
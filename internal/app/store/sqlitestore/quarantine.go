@@ -0,0 +1,86 @@
+package sqlitestore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+)
+
+type QuarantineRepository struct {
+	store *Store
+}
+
+func (r *QuarantineRepository) AddQuarantined(ctx context.Context, price model.QuarantinedPrice) (model.QuarantinedPrice, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	err := r.store.db.QueryRowContext(ctx,
+		`INSERT INTO quarantined_prices (coin_id, price, timestamp, reason, detail, created_at)
+		 VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+		 RETURNING id`,
+		price.CoinID, price.Price, price.Timestamp, price.Reason, price.Detail, price.CreatedAt,
+	).Scan(&price.ID)
+	if err != nil {
+		return model.QuarantinedPrice{}, err
+	}
+	return price, nil
+}
+
+func (r *QuarantineRepository) ListQuarantined(ctx context.Context, coin string) ([]model.QuarantinedPrice, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.db.QueryContext(ctx,
+		`SELECT id, coin_id, price, timestamp, reason, detail, created_at
+		 FROM quarantined_prices
+		 WHERE ?1 = '' OR coin_id = ?1
+		 ORDER BY timestamp, id`,
+		coin,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []model.QuarantinedPrice{}
+	for rows.Next() {
+		var p model.QuarantinedPrice
+		if err := rows.Scan(&p.ID, &p.CoinID, &p.Price, &p.Timestamp, &p.Reason, &p.Detail, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+func (r *QuarantineRepository) GetQuarantined(ctx context.Context, id int64) (model.QuarantinedPrice, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var p model.QuarantinedPrice
+	err := r.store.db.QueryRowContext(ctx,
+		`SELECT id, coin_id, price, timestamp, reason, detail, created_at
+		 FROM quarantined_prices
+		 WHERE id = ?1`,
+		id,
+	).Scan(&p.ID, &p.CoinID, &p.Price, &p.Timestamp, &p.Reason, &p.Detail, &p.CreatedAt)
+	if err != nil {
+		return model.QuarantinedPrice{}, err
+	}
+	return p, nil
+}
+
+func (r *QuarantineRepository) DeleteQuarantined(ctx context.Context, id int64) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.db.ExecContext(ctx,
+		"DELETE FROM quarantined_prices WHERE id = ?1",
+		id,
+	)
+	return err
+}
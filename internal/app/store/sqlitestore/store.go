@@ -0,0 +1,117 @@
+package sqlitestore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"database/sql"
+	_ "modernc.org/sqlite"
+	"time"
+)
+
+// Store - реализация sqlstore.StoreInterface на встроенном SQLite
+type Store struct {
+	db                   *sql.DB
+	queryTimeout         time.Duration // Таймаут одного обращения к БД, 0 - без таймаута
+	currencyRepository   sqlstore.CurrencyInterface
+	marketRepository     sqlstore.MarketInterface
+	portfolioRepository  sqlstore.PortfolioInterface
+	quarantineRepository sqlstore.QuarantineInterface
+	sourceRepository     sqlstore.SourceInterface
+}
+
+// Open открывает файл базы SQLite с включенными внешними ключами и WAL
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file:"+path+
+		"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite допускает одного писателя, единственное соединение исключает "database is locked"
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func New(db *sql.DB, queryTimeout time.Duration) *Store {
+	return &Store{
+		db:           db,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// withTimeout ограничивает контекст запроса таймаутом из конфигурации
+func (s *Store) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
+func (s *Store) Currency() sqlstore.CurrencyInterface {
+	if s.currencyRepository != nil {
+		return s.currencyRepository
+	}
+
+	s.currencyRepository = &CurrencyRepository{
+		store: s,
+	}
+
+	return s.currencyRepository
+}
+
+func (s *Store) Market() sqlstore.MarketInterface {
+	if s.marketRepository != nil {
+		return s.marketRepository
+	}
+
+	s.marketRepository = &MarketRepository{
+		store: s,
+	}
+
+	return s.marketRepository
+}
+
+func (s *Store) Portfolio() sqlstore.PortfolioInterface {
+	if s.portfolioRepository != nil {
+		return s.portfolioRepository
+	}
+
+	s.portfolioRepository = &PortfolioRepository{
+		store: s,
+	}
+
+	return s.portfolioRepository
+}
+
+func (s *Store) Quarantine() sqlstore.QuarantineInterface {
+	if s.quarantineRepository != nil {
+		return s.quarantineRepository
+	}
+
+	s.quarantineRepository = &QuarantineRepository{
+		store: s,
+	}
+
+	return s.quarantineRepository
+}
+
+func (s *Store) Source() sqlstore.SourceInterface {
+	if s.sourceRepository != nil {
+		return s.sourceRepository
+	}
+
+	s.sourceRepository = &SourceRepository{
+		store: s,
+	}
+
+	return s.sourceRepository
+}
+
+// Close закрывает файл базы
+func (s *Store) Close() error {
+	return s.db.Close()
+}
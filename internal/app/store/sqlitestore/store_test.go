@@ -0,0 +1,29 @@
+package sqlitestore_test
+
+import (
+	"cryptoObserver/internal/app/migrations"
+	"cryptoObserver/internal/app/store/sqlitestore"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/storetest"
+	"github.com/sirupsen/logrus"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) sqlstore.StoreInterface {
+		db, err := sqlitestore.Open(filepath.Join(t.TempDir(), "observer.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		log := logrus.New()
+		log.SetOutput(io.Discard)
+		if err := migrations.SQLite.Migrate(db, log); err != nil {
+			t.Fatal(err)
+		}
+		store := sqlitestore.New(db, 0)
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
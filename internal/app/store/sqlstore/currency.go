@@ -1,63 +1,155 @@
 package sqlstore
 
 import (
+	"context"
 	"cryptoObserver/internal/app/model"
-	"cryptoObserver/internal/app/store/sqlstore/utils"
 	"database/sql"
 )
 
 type CurrencyInterface interface {
-	AddCurrency(currency string) error
-	RemoveCurrency(currency string) error
-	GetPrice(coin string, timestamp int64) (model.Decimal, error)
-	GetCurrencyList() ([]string, error)
-	UpdatePrice(coin string, price model.Decimal, timestamp int64) error
+	AddCurrency(ctx context.Context, currency string) error
+	RemoveCurrency(ctx context.Context, currency string) error
+	GetPrice(ctx context.Context, coin string, timestamp int64) (model.Decimal, error)
+	// GetNearestPrice возвращает ближайшую к timestamp точку, нулевую - если точек нет
+	GetNearestPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error)
+	// GetLastPrice возвращает последнюю точку не позже timestamp, нулевую - если ее нет
+	GetLastPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error)
+	// GetPriceSeries возвращает точки за период [from, to], упорядоченные по времени
+	GetPriceSeries(ctx context.Context, coin string, from, to int64) ([]model.PricePoint, error)
+	GetCurrencyList(ctx context.Context) ([]string, error)
+	// UpdatePrice сохраняет точку; source - провайдер, от которого она получена
+	UpdatePrice(ctx context.Context, coin string, price model.Decimal, timestamp int64, source string) error
 }
 
 type CurrencyRepository struct {
 	store *Store
 }
 
-func (r *CurrencyRepository) AddCurrency(currency string) error {
-	_, err := r.store.db.Exec(
+func (r *CurrencyRepository) AddCurrency(ctx context.Context, currency string) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.db.ExecContext(ctx,
 		"INSERT INTO currencies (symbol) VALUES ($1) ON CONFLICT (symbol) DO NOTHING",
 		currency,
 	)
 	return err
 }
 
-func (r *CurrencyRepository) RemoveCurrency(currency string) error {
-	_, err := r.store.db.Exec(
+func (r *CurrencyRepository) RemoveCurrency(ctx context.Context, currency string) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.db.ExecContext(ctx,
 		"DELETE FROM currencies WHERE symbol = $1",
 		currency,
 	)
 	return err
 }
 
-func (r *CurrencyRepository) GetPrice(coin string, timestamp int64) (model.Decimal, error) {
-	var price string
-	err := r.store.db.QueryRow(
-		`SELECT cp.price
+func (r *CurrencyRepository) GetPrice(ctx context.Context, coin string, timestamp int64) (model.Decimal, error) {
+	point, err := r.GetNearestPrice(ctx, coin, timestamp)
+	return point.Price, err
+}
+
+func (r *CurrencyRepository) GetNearestPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var point model.PricePoint
+	// Два поиска по индексу (currency_id, timestamp): ближайшая точка не позже
+	// и не раньше запрошенного момента, из них выбираем ближайшую.
+	// ORDER BY ABS(timestamp - $2) по всей таблице индекс использовать не может.
+	err := r.store.db.QueryRowContext(ctx,
+		`WITH c AS (SELECT id FROM currencies WHERE symbol = $1)
+		 SELECT nearest.price, nearest.timestamp FROM (
+		     (SELECT cp.price, cp.timestamp
+		      FROM currency_prices cp
+		      WHERE cp.currency_id = (SELECT id FROM c) AND cp.timestamp <= $2
+		      ORDER BY cp.timestamp DESC
+		      LIMIT 1)
+		     UNION ALL
+		     (SELECT cp.price, cp.timestamp
+		      FROM currency_prices cp
+		      WHERE cp.currency_id = (SELECT id FROM c) AND cp.timestamp >= $2
+		      ORDER BY cp.timestamp ASC
+		      LIMIT 1)
+		 ) nearest
+		 ORDER BY ABS(nearest.timestamp - $2), nearest.timestamp
+		 LIMIT 1`,
+		coin, timestamp,
+	).Scan(&point.Price, &point.Timestamp)
+	if err == sql.ErrNoRows {
+		return model.PricePoint{}, nil
+	}
+	if err != nil {
+		return model.PricePoint{}, err
+	}
+	return point, nil
+}
+
+func (r *CurrencyRepository) GetLastPrice(ctx context.Context, coin string, timestamp int64) (model.PricePoint, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var point model.PricePoint
+	err := r.store.db.QueryRowContext(ctx,
+		`SELECT cp.price, cp.timestamp
 		 FROM currency_prices cp
 		 JOIN currencies c ON cp.currency_id = c.id
-		 WHERE c.symbol = $1
-		 ORDER BY ABS(cp.timestamp - $2)
+		 WHERE c.symbol = $1 AND cp.timestamp <= $2
+		 ORDER BY cp.timestamp DESC
 		 LIMIT 1`,
 		coin, timestamp,
-	).Scan(&price)
+	).Scan(&point.Price, &point.Timestamp)
 	if err == sql.ErrNoRows {
-		return model.Decimal{}, nil
+		return model.PricePoint{}, nil
 	}
-	decimal, err := utils.ParseDecimal(price)
 	if err != nil {
-		return model.Decimal{}, err
+		return model.PricePoint{}, err
 	}
-	return decimal, nil
+	return point, nil
 }
 
-func (r *CurrencyRepository) GetCurrencyList() ([]string, error) {
+func (r *CurrencyRepository) GetPriceSeries(ctx context.Context, coin string, from, to int64) ([]model.PricePoint, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.db.QueryContext(ctx,
+		`SELECT cp.price, cp.timestamp
+		 FROM currency_prices cp
+		 JOIN currencies c ON cp.currency_id = c.id
+		 WHERE c.symbol = $1 AND cp.timestamp BETWEEN $2 AND $3
+		 ORDER BY cp.timestamp`,
+		coin, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := []model.PricePoint{}
+	for rows.Next() {
+		var point model.PricePoint
+		if err := rows.Scan(&point.Price, &point.Timestamp); err != nil {
+			return nil, err
+		}
+		series = append(series, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (r *CurrencyRepository) GetCurrencyList(ctx context.Context) ([]string, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
 	var currencies []string
-	rows, err := r.store.db.Query("SELECT symbol FROM currencies")
+	rows, err := r.store.db.QueryContext(ctx, "SELECT symbol FROM currencies")
 	if err != nil {
 		return nil, err
 	}
@@ -78,10 +170,13 @@ func (r *CurrencyRepository) GetCurrencyList() ([]string, error) {
 	return currencies, nil
 }
 
-func (r *CurrencyRepository) UpdatePrice(coin string, price model.Decimal, timestamp int64) error {
+func (r *CurrencyRepository) UpdatePrice(ctx context.Context, coin string, price model.Decimal, timestamp int64, source string) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
 	// Получаем id валюты по символу
 	var currencyID int
-	err := r.store.db.QueryRow(
+	err := r.store.db.QueryRowContext(ctx,
 		"SELECT id FROM currencies WHERE symbol = $1",
 		coin,
 	).Scan(&currencyID)
@@ -89,15 +184,12 @@ func (r *CurrencyRepository) UpdatePrice(coin string, price model.Decimal, times
 		return err
 	}
 
-	// Преобразуем Decimal в строку
-	priceStr := utils.DecimalToString(price)
-
 	// Вставляем или обновляем цену
-	_, err = r.store.db.Exec(
-		`INSERT INTO currency_prices (currency_id, price, timestamp)
-   VALUES ($1, $2, $3)
-   ON CONFLICT (currency_id, timestamp) DO UPDATE SET price = EXCLUDED.price`,
-		currencyID, priceStr, timestamp,
+	_, err = r.store.db.ExecContext(ctx,
+		`INSERT INTO currency_prices (currency_id, price, timestamp, source)
+   VALUES ($1, $2, $3, $4)
+   ON CONFLICT (currency_id, timestamp) DO UPDATE SET price = EXCLUDED.price, source = EXCLUDED.source`,
+		currencyID, price, timestamp, source,
 	)
 	return err
 }
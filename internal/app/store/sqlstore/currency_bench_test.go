@@ -0,0 +1,110 @@
+package sqlstore_test
+
+import (
+	"context"
+	"cryptoObserver/internal/app/migrations"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/storetest"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"testing"
+)
+
+const (
+	benchCoins    = 10
+	benchStep     = 60 // Шаг точек, сек
+	benchRowsEnv  = "TEST_BENCH_ROWS"
+	benchRowsDflt = 2_000_000
+)
+
+// legacyNearestQuery - прежний поиск ближайшей точки, для сравнения
+const legacyNearestQuery = `SELECT cp.price, cp.timestamp
+	FROM currency_prices cp
+	JOIN currencies c ON cp.currency_id = c.id
+	WHERE c.symbol = $1
+	ORDER BY ABS(cp.timestamp - $2)
+	LIMIT 1`
+
+// openBenchStore заполняет временную схему benchRows точками (по умолчанию 2 млн) на benchCoins валют
+func openBenchStore(b *testing.B) (*sql.DB, *sqlstore.Store, int64) {
+	b.Helper()
+	db := storetest.OpenPostgres(b, storetest.PostgresDSNEnv)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	if err := migrations.Postgres.Migrate(db, log); err != nil {
+		b.Fatal(err)
+	}
+
+	rows := benchRowsDflt
+	if value := os.Getenv(benchRowsEnv); value != "" {
+		var err error
+		if rows, err = strconv.Atoi(value); err != nil {
+			b.Fatalf("%s: %v", benchRowsEnv, err)
+		}
+	}
+	perCoin := int64(rows / benchCoins)
+	_, err := db.Exec(`INSERT INTO currencies (symbol) SELECT 'coin-' || g FROM generate_series(1, $1) g`, benchCoins)
+	if err != nil {
+		b.Fatal(err)
+	}
+	_, err = db.Exec(
+		`INSERT INTO currency_prices (currency_id, price, timestamp)
+		 SELECT c.id, round((random() * 100000)::numeric, 8), t * $2
+		 FROM currencies c, generate_series(1, $1) t`,
+		perCoin, benchStep,
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := db.Exec("ANALYZE currency_prices"); err != nil {
+		b.Fatal(err)
+	}
+	return db, sqlstore.New(db, 0), perCoin * benchStep
+}
+
+// BenchmarkGetNearestPrice сравнивает два поиска по индексу с сортировкой по ABS.
+// Запускается при заданном TEST_POSTGRES_DSN:
+//
+//	TEST_POSTGRES_DSN=... go test -run XXX -bench GetNearestPrice ./internal/app/store/sqlstore/
+func BenchmarkGetNearestPrice(b *testing.B) {
+	db, store, span := openBenchStore(b)
+	ctx := context.Background()
+	// Момент между точками, чтобы у обоих запросов был единственный ответ
+	randomTimestamp := func() int64 { return rand.Int64N(span-benchStep) + benchStep + 17 }
+
+	for i := 0; i < 20; i++ {
+		ts := randomTimestamp()
+		point, err := store.Currency().GetNearestPrice(ctx, "coin-5", ts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var legacy model.PricePoint
+		if err := db.QueryRow(legacyNearestQuery, "coin-5", ts).Scan(&legacy.Price, &legacy.Timestamp); err != nil {
+			b.Fatal(err)
+		}
+		if point.Timestamp != legacy.Timestamp || point.Price.Cmp(legacy.Price) != 0 {
+			b.Fatalf("at %d: GetNearestPrice = %+v, legacy query = %+v", ts, point, legacy)
+		}
+	}
+
+	b.Run("index-probes", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := store.Currency().GetNearestPrice(ctx, "coin-5", randomTimestamp()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("order-by-abs", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var point model.PricePoint
+			if err := db.QueryRow(legacyNearestQuery, "coin-5", randomTimestamp()).Scan(&point.Price, &point.Timestamp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
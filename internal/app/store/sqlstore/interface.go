@@ -1,13 +1,21 @@
 package sqlstore
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 type StoreInterface interface {
 	Currency() CurrencyInterface
+	Market() MarketInterface
+	Portfolio() PortfolioInterface
+	Quarantine() QuarantineInterface
+	Source() SourceInterface
+	Close() error
 }
 
 type DBInterface interface {
-	Query(query string, args ...interface{}) (*sql.Rows, error)
-	QueryRow(query string, args ...interface{}) *sql.Row
-	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
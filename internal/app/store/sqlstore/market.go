@@ -0,0 +1,80 @@
+package sqlstore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+)
+
+type MarketInterface interface {
+	SaveSnapshot(ctx context.Context, coin string, snapshot model.MarketSnapshot) error
+	GetSnapshots(ctx context.Context, coin string, from, to int64) ([]model.MarketSnapshot, error)
+}
+
+type MarketRepository struct {
+	store *Store
+}
+
+func (r *MarketRepository) SaveSnapshot(ctx context.Context, coin string, snapshot model.MarketSnapshot) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.db.ExecContext(ctx,
+		`INSERT INTO market_snapshots (
+			currency_id, timestamp, price, market_cap, total_volume, high_24h, low_24h,
+			price_change_percentage_24h, circulating_supply, ath
+		)
+		SELECT c.id, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		FROM currencies c
+		WHERE c.symbol = $1
+		ON CONFLICT (currency_id, timestamp) DO UPDATE SET
+			price = EXCLUDED.price,
+			market_cap = EXCLUDED.market_cap,
+			total_volume = EXCLUDED.total_volume,
+			high_24h = EXCLUDED.high_24h,
+			low_24h = EXCLUDED.low_24h,
+			price_change_percentage_24h = EXCLUDED.price_change_percentage_24h,
+			circulating_supply = EXCLUDED.circulating_supply,
+			ath = EXCLUDED.ath`,
+		coin, snapshot.Timestamp, snapshot.Price, snapshot.MarketCap, snapshot.TotalVolume,
+		snapshot.High24h, snapshot.Low24h, snapshot.PriceChangePercentage24h,
+		snapshot.CirculatingSupply, snapshot.ATH,
+	)
+	return err
+}
+
+func (r *MarketRepository) GetSnapshots(ctx context.Context, coin string, from, to int64) ([]model.MarketSnapshot, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.db.QueryContext(ctx,
+		`SELECT ms.timestamp, ms.price, ms.market_cap, ms.total_volume, ms.high_24h, ms.low_24h,
+		        ms.price_change_percentage_24h, ms.circulating_supply, ms.ath
+		 FROM market_snapshots ms
+		 JOIN currencies c ON ms.currency_id = c.id
+		 WHERE c.symbol = $1 AND ms.timestamp BETWEEN $2 AND $3
+		 ORDER BY ms.timestamp`,
+		coin, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []model.MarketSnapshot{}
+	for rows.Next() {
+		var s model.MarketSnapshot
+		if err := rows.Scan(
+			&s.Timestamp, &s.Price, &s.MarketCap, &s.TotalVolume, &s.High24h, &s.Low24h,
+			&s.PriceChangePercentage24h, &s.CirculatingSupply, &s.ATH,
+		); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
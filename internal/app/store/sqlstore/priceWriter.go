@@ -0,0 +1,312 @@
+package sqlstore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Максимум строк в одном INSERT: 4 параметра на строку, лимит Postgres - 65535 параметров
+	maxRowsPerInsert = 1000
+	// Во сколько раз буфер может превысить maxRows, пока БД недоступна
+	maxBufferedBatches = 10
+)
+
+// priceKey - уникальный ключ точки, как UNIQUE(currency_id, timestamp)
+type priceKey struct {
+	coin      string
+	timestamp int64
+}
+
+// pendingPrice - точка, ожидающая записи
+type pendingPrice struct {
+	price  model.Decimal
+	source string
+}
+
+// BufferedCurrencyRepository - CurrencyRepository с отложенной пакетной записью цен.
+// UpdatePrice только кладет точку в буфер; буфер сбрасывается многострочным INSERT
+// раз в interval или при накоплении maxRows точек, а также при Close.
+// Соответствие символ -> id валюты кешируется. Точки из буфера видны в GetPrice
+// только после сброса.
+type BufferedCurrencyRepository struct {
+	*CurrencyRepository
+	maxRows  int
+	interval time.Duration
+	log      *logrus.Logger
+
+	mu      sync.Mutex
+	pending map[priceKey]pendingPrice // Повторная точка в пределах пакета перезаписывает прежнюю
+	closed  bool
+
+	idsMu sync.RWMutex
+	ids   map[string]int // Кеш символ -> id
+
+	flushMu sync.Mutex // Сбросы выполняются последовательно
+	full    chan struct{}
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newBufferedCurrencyRepository(repo *CurrencyRepository, maxRows int, interval time.Duration, log *logrus.Logger) *BufferedCurrencyRepository {
+	r := &BufferedCurrencyRepository{
+		CurrencyRepository: repo,
+		maxRows:            maxRows,
+		interval:           interval,
+		log:                log,
+		pending:            make(map[priceKey]pendingPrice),
+		ids:                make(map[string]int),
+		full:               make(chan struct{}, 1),
+		stop:               make(chan struct{}),
+	}
+	r.wg.Add(1)
+	go r.flushLoop()
+	return r
+}
+
+func (r *BufferedCurrencyRepository) UpdatePrice(ctx context.Context, coin string, price model.Decimal, timestamp int64, source string) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return r.CurrencyRepository.UpdatePrice(ctx, coin, price, timestamp, source)
+	}
+	r.pending[priceKey{coin: coin, timestamp: timestamp}] = pendingPrice{price: price, source: source}
+	full := len(r.pending) >= r.maxRows
+	r.mu.Unlock()
+
+	if full {
+		select {
+		case r.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (r *BufferedCurrencyRepository) RemoveCurrency(ctx context.Context, currency string) error {
+	if err := r.CurrencyRepository.RemoveCurrency(ctx, currency); err != nil {
+		return err
+	}
+	r.ForgetCurrency(currency)
+	return nil
+}
+
+// Фоновый сброс буфера
+func (r *BufferedCurrencyRepository) flushLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		case <-r.full:
+		}
+		// Сброс не привязан к контексту запроса, его ограничивает только таймаут запроса к БД
+		if err := r.Flush(context.Background()); err != nil {
+			r.log.Errorf("Failed to flush price buffer: %v", err)
+		}
+	}
+}
+
+// Flush записывает накопленные точки в БД.
+// Если валюту удалили или пересоздали на другой реплике, кешированный id устаревает
+// и INSERT нарушает внешний ключ: тогда id валют пакета сбрасываются и пакет
+// пишется заново со свежими id, а точки удаленных валют отбрасываются.
+func (r *BufferedCurrencyRepository) Flush(ctx context.Context) error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[priceKey]pendingPrice)
+	r.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := r.writeBatch(ctx, batch)
+	if isForeignKeyViolation(err) {
+		r.forgetIDs(batch)
+		err = r.writeBatch(ctx, batch)
+	}
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			// Валюту удалили между загрузкой id и INSERT, следующий сброс загрузит их заново
+			r.forgetIDs(batch)
+		}
+		r.requeue(batch)
+		return err
+	}
+	return nil
+}
+
+// writeBatch пишет пакет многострочными INSERT, пропуская точки неотслеживаемых валют.
+// Повторная запись уже сохраненных частей безопасна: это upsert.
+func (r *BufferedCurrencyRepository) writeBatch(ctx context.Context, batch map[priceKey]pendingPrice) error {
+	ids, err := r.resolveIDs(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	args := make([]interface{}, 0, 4*min(len(batch), maxRowsPerInsert))
+	dropped := 0
+	for key, price := range batch {
+		id, ok := ids[key.coin]
+		if !ok {
+			// Валюту удалили, пока точка лежала в буфере
+			dropped++
+			continue
+		}
+		args = append(args, id, price.price, key.timestamp, price.source)
+		if len(args) == 4*maxRowsPerInsert {
+			if err := r.insertPrices(ctx, args); err != nil {
+				return err
+			}
+			args = args[:0]
+		}
+	}
+	if len(args) > 0 {
+		if err := r.insertPrices(ctx, args); err != nil {
+			return err
+		}
+	}
+	if dropped > 0 {
+		r.log.Warnf("Dropped %d buffered prices of removed currencies", dropped)
+	}
+	return nil
+}
+
+// ForgetCurrency сбрасывает кешированный id валюты.
+// Вызывается при удалении или добавлении валюты, в том числе другой репликой.
+func (r *BufferedCurrencyRepository) ForgetCurrency(coin string) {
+	r.idsMu.Lock()
+	delete(r.ids, coin)
+	r.idsMu.Unlock()
+}
+
+// forgetIDs сбрасывает кешированные id всех валют пакета
+func (r *BufferedCurrencyRepository) forgetIDs(batch map[priceKey]pendingPrice) {
+	r.idsMu.Lock()
+	defer r.idsMu.Unlock()
+	for key := range batch {
+		delete(r.ids, key.coin)
+	}
+}
+
+// isForeignKeyViolation проверяет, что INSERT сослался на несуществующую валюту
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+// requeue возвращает несохраненный пакет в буфер для следующей попытки.
+// Уже записанные части пакета перезапишутся тем же значением (upsert).
+// Более свежие точки с тем же ключом не затираются.
+func (r *BufferedCurrencyRepository) requeue(batch map[priceKey]pendingPrice) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dropped := 0
+	for key, price := range batch {
+		if _, exists := r.pending[key]; exists {
+			continue
+		}
+		if len(r.pending) >= maxBufferedBatches*r.maxRows {
+			dropped++
+			continue
+		}
+		r.pending[key] = price
+	}
+	if dropped > 0 {
+		r.log.Errorf("Price buffer is full, dropped %d prices", dropped)
+	}
+}
+
+// resolveIDs возвращает id валют пакета, догружая недостающие одним запросом
+func (r *BufferedCurrencyRepository) resolveIDs(ctx context.Context, batch map[priceKey]pendingPrice) (map[string]int, error) {
+	ids := make(map[string]int)
+	unknown := make(map[string]struct{})
+	r.idsMu.RLock()
+	for key := range batch {
+		if id, ok := r.ids[key.coin]; ok {
+			ids[key.coin] = id
+		} else {
+			unknown[key.coin] = struct{}{}
+		}
+	}
+	r.idsMu.RUnlock()
+	if len(unknown) == 0 {
+		return ids, nil
+	}
+	missing := make([]string, 0, len(unknown))
+	for coin := range unknown {
+		missing = append(missing, coin)
+	}
+
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.db.QueryContext(ctx, "SELECT id, symbol FROM currencies WHERE symbol = ANY($1)", pq.Array(missing))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r.idsMu.Lock()
+	defer r.idsMu.Unlock()
+	for rows.Next() {
+		var id int
+		var symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		r.ids[symbol] = id
+		ids[symbol] = id
+	}
+	return ids, rows.Err()
+}
+
+// insertPrices выполняет многострочный INSERT; args - четверки (currency_id, price, timestamp, source)
+func (r *BufferedCurrencyRepository) insertPrices(ctx context.Context, args []interface{}) error {
+	var query strings.Builder
+	query.WriteString("INSERT INTO currency_prices (currency_id, price, timestamp, source) VALUES ")
+	for i := 0; i < len(args); i += 4 {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "($%d, $%d, $%d, $%d)", i+1, i+2, i+3, i+4)
+	}
+	query.WriteString(" ON CONFLICT (currency_id, timestamp) DO UPDATE SET price = EXCLUDED.price, source = EXCLUDED.source")
+
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.db.ExecContext(ctx, query.String(), args...)
+	return err
+}
+
+// Close останавливает фоновый сброс и записывает остаток буфера
+func (r *BufferedCurrencyRepository) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.stop)
+	r.wg.Wait()
+	return r.Flush(context.Background())
+}
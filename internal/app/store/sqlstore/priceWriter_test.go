@@ -0,0 +1,111 @@
+package sqlstore_test
+
+import (
+	"context"
+	"cryptoObserver/internal/app/migrations"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/storetest"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"io"
+	"testing"
+	"time"
+)
+
+// openBufferedStore возвращает хранилище с буфером записи, который сбрасывается только явно
+func openBufferedStore(t *testing.T) (*sql.DB, *sqlstore.BufferedCurrencyRepository) {
+	t.Helper()
+	db := storetest.OpenPostgres(t, storetest.PostgresDSNEnv)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	if err := migrations.Postgres.Migrate(db, log); err != nil {
+		t.Fatal(err)
+	}
+	store := sqlstore.New(db, 0)
+	store.EnableWriteBuffer(1000, time.Hour, log)
+	t.Cleanup(func() { _ = store.Close() })
+	return db, store.Currency().(*sqlstore.BufferedCurrencyRepository)
+}
+
+// writeAndFlush кладет точку в буфер и сбрасывает его, id валют попадают в кеш
+func writeAndFlush(t *testing.T, writer *sqlstore.BufferedCurrencyRepository, coin string, timestamp int64) {
+	t.Helper()
+	ctx := context.Background()
+	if err := writer.UpdatePrice(ctx, coin, model.NewDecimal(timestamp, 0), timestamp, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFlushRecoversFromStaleID имитирует другую реплику, которая удаляет и заново
+// добавляет валюту: кешированный id устаревает, но пакет все равно записывается
+func TestFlushRecoversFromStaleID(t *testing.T) {
+	db, writer := openBufferedStore(t)
+	ctx := context.Background()
+	for _, coin := range []string{"bitcoin", "ethereum", "dogecoin"} {
+		if err := writer.AddCurrency(ctx, coin); err != nil {
+			t.Fatal(err)
+		}
+		writeAndFlush(t, writer, coin, 100)
+	}
+
+	// Изменения в обход этого процесса: bitcoin пересоздан с новым id, dogecoin удален
+	if _, err := db.Exec("DELETE FROM currencies WHERE symbol IN ('bitcoin', 'dogecoin')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO currencies (symbol) VALUES ('bitcoin')"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, coin := range []string{"bitcoin", "ethereum", "dogecoin"} {
+		if err := writer.UpdatePrice(ctx, coin, model.NewDecimal(200, 0), 200, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Flush(ctx); err != nil {
+		t.Fatalf("Flush with stale ids: %v", err)
+	}
+	// Точки удаленной валюты отброшены, а не возвращены в буфер
+	if err := writer.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, coin := range []string{"bitcoin", "ethereum"} {
+		point, err := writer.GetLastPrice(ctx, coin, 1000)
+		if err != nil || point.Timestamp != 200 {
+			t.Errorf("%s: last price %+v, %v; want the point at 200", coin, point, err)
+		}
+	}
+	var rows int
+	if err := db.QueryRow("SELECT count(*) FROM currency_prices").Scan(&rows); err != nil || rows != 3 {
+		t.Errorf("currency_prices has %d rows, want 3 (%v)", rows, err)
+	}
+}
+
+// TestForgetCurrency проверяет сброс кеша по событию: точка пишется под новым id
+// без единой ошибки внешнего ключа
+func TestForgetCurrency(t *testing.T) {
+	db, writer := openBufferedStore(t)
+	ctx := context.Background()
+	if err := writer.AddCurrency(ctx, "bitcoin"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndFlush(t, writer, "bitcoin", 100)
+
+	if _, err := db.Exec("DELETE FROM currencies WHERE symbol = 'bitcoin'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO currencies (symbol) VALUES ('bitcoin')"); err != nil {
+		t.Fatal(err)
+	}
+	writer.ForgetCurrency("bitcoin")
+	writeAndFlush(t, writer, "bitcoin", 200)
+
+	point, err := writer.GetLastPrice(ctx, "bitcoin", 1000)
+	if err != nil || point.Timestamp != 200 {
+		t.Fatalf("last price %+v, %v; want the point at 200", point, err)
+	}
+}
@@ -0,0 +1,77 @@
+package sqlstore
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"fmt"
+	"strings"
+)
+
+// SourceInterface хранит цены отдельных провайдеров, собранные в режиме консенсуса
+type SourceInterface interface {
+	SaveSourcePrices(ctx context.Context, prices []model.SourcePrice) error
+	GetSourcePrices(ctx context.Context, coin string, from, to int64) ([]model.SourcePrice, error)
+}
+
+type SourceRepository struct {
+	store *Store
+}
+
+// Число колонок в одной строке source_prices при вставке
+const sourcePriceColumns = 7
+
+func (r *SourceRepository) SaveSourcePrices(ctx context.Context, prices []model.SourcePrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	// Провайдеров немного, поэтому все цены одной точки пишутся одним INSERT
+	var query strings.Builder
+	query.WriteString("INSERT INTO source_prices (coin_id, source, price, consensus, deviation_percent, flagged, timestamp) VALUES ")
+	args := make([]interface{}, 0, len(prices)*sourcePriceColumns)
+	for i, p := range prices {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		n := i * sourcePriceColumns
+		fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+		args = append(args, p.CoinID, p.Source, p.Price, p.Consensus, p.DeviationPercent, p.Flagged, p.Timestamp)
+	}
+
+	_, err := r.store.db.ExecContext(ctx, query.String(), args...)
+	return err
+}
+
+func (r *SourceRepository) GetSourcePrices(ctx context.Context, coin string, from, to int64) ([]model.SourcePrice, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.db.QueryContext(ctx,
+		`SELECT coin_id, source, price, consensus, deviation_percent, flagged, timestamp
+		 FROM source_prices
+		 WHERE coin_id = $1 AND timestamp BETWEEN $2 AND $3
+		 ORDER BY timestamp, source`,
+		coin, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []model.SourcePrice{}
+	for rows.Next() {
+		var p model.SourcePrice
+		if err := rows.Scan(&p.CoinID, &p.Source, &p.Price, &p.Consensus, &p.DeviationPercent, &p.Flagged, &p.Timestamp); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
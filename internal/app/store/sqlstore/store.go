@@ -1,18 +1,38 @@
 package sqlstore
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"time"
+)
 
 type Store struct {
-	db                 *sql.DB
-	currencyRepository CurrencyInterface
+	db                   *sql.DB
+	queryTimeout         time.Duration // Таймаут одного обращения к БД, 0 - без таймаута
+	currencyRepository   CurrencyInterface
+	marketRepository     MarketInterface
+	portfolioRepository  PortfolioInterface
+	quarantineRepository QuarantineInterface
+	sourceRepository     SourceInterface
+	priceWriter          *BufferedCurrencyRepository
 }
 
-func New(db *sql.DB) *Store {
+func New(db *sql.DB, queryTimeout time.Duration) *Store {
 	return &Store{
-		db: db,
+		db:           db,
+		queryTimeout: queryTimeout,
 	}
 }
 
+// withTimeout ограничивает контекст запроса таймаутом из конфигурации
+func (s *Store) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
 func (s *Store) Currency() CurrencyInterface {
 	if s.currencyRepository != nil {
 		return s.currencyRepository
@@ -24,3 +44,76 @@ func (s *Store) Currency() CurrencyInterface {
 
 	return s.currencyRepository
 }
+
+// EnableWriteBuffer включает пакетную запись цен: точки копятся в памяти и
+// пишутся в БД каждые interval или при накоплении maxRows точек.
+// Должен вызываться до первого обращения к Currency.
+func (s *Store) EnableWriteBuffer(maxRows int, interval time.Duration, log *logrus.Logger) {
+	s.priceWriter = newBufferedCurrencyRepository(&CurrencyRepository{store: s}, maxRows, interval, log)
+	s.currencyRepository = s.priceWriter
+}
+
+// ForgetCurrency сбрасывает кешированный id валюты в буфере записи, если он включен
+func (s *Store) ForgetCurrency(coin string) {
+	if s.priceWriter != nil {
+		s.priceWriter.ForgetCurrency(coin)
+	}
+}
+
+// Close сбрасывает буфер цен и закрывает соединение с БД
+func (s *Store) Close() error {
+	if s.priceWriter != nil {
+		if err := s.priceWriter.Close(); err != nil {
+			return err
+		}
+	}
+	return s.db.Close()
+}
+
+func (s *Store) Market() MarketInterface {
+	if s.marketRepository != nil {
+		return s.marketRepository
+	}
+
+	s.marketRepository = &MarketRepository{
+		store: s,
+	}
+
+	return s.marketRepository
+}
+
+func (s *Store) Portfolio() PortfolioInterface {
+	if s.portfolioRepository != nil {
+		return s.portfolioRepository
+	}
+
+	s.portfolioRepository = &PortfolioRepository{
+		store: s,
+	}
+
+	return s.portfolioRepository
+}
+
+func (s *Store) Quarantine() QuarantineInterface {
+	if s.quarantineRepository != nil {
+		return s.quarantineRepository
+	}
+
+	s.quarantineRepository = &QuarantineRepository{
+		store: s,
+	}
+
+	return s.quarantineRepository
+}
+
+func (s *Store) Source() SourceInterface {
+	if s.sourceRepository != nil {
+		return s.sourceRepository
+	}
+
+	s.sourceRepository = &SourceRepository{
+		store: s,
+	}
+
+	return s.sourceRepository
+}
@@ -0,0 +1,23 @@
+package sqlstore_test
+
+import (
+	"cryptoObserver/internal/app/migrations"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"cryptoObserver/internal/app/store/storetest"
+	"github.com/sirupsen/logrus"
+	"io"
+	"testing"
+)
+
+// TestConformance запускается при заданном TEST_POSTGRES_DSN, каждая проверка - в своей схеме
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) sqlstore.StoreInterface {
+		db := storetest.OpenPostgres(t, storetest.PostgresDSNEnv)
+		log := logrus.New()
+		log.SetOutput(io.Discard)
+		if err := migrations.Postgres.Migrate(db, log); err != nil {
+			t.Fatal(err)
+		}
+		return sqlstore.New(db, 0)
+	})
+}
@@ -0,0 +1,443 @@
+package storetest
+
+import (
+	"context"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// Opener возвращает новое пустое хранилище для одного теста
+type Opener func(t *testing.T) sqlstore.StoreInterface
+
+// conformanceCase - одна проверка контракта StoreInterface
+type conformanceCase struct {
+	name string
+	run  func(t *testing.T, store sqlstore.StoreInterface)
+}
+
+// Run прогоняет общий набор проверок на реализации StoreInterface.
+// Каждая проверка получает свое пустое хранилище из open.
+func Run(t *testing.T, open Opener) {
+	for _, tc := range conformanceCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, open(t))
+		})
+	}
+}
+
+var conformanceCases = []conformanceCase{
+	{"CurrencyList", testCurrencyList},
+	{"UpdatePriceUntracked", testUpdatePriceUntracked},
+	{"UpdatePriceUpsert", testUpdatePriceUpsert},
+	{"NearestPrice", testNearestPrice},
+	{"LastPriceAndSeries", testLastPriceAndSeries},
+	{"DecimalPrecision", testDecimalPrecision},
+	{"RemoveCascade", testRemoveCascade},
+	{"Snapshots", testSnapshots},
+	{"Portfolios", testPortfolios},
+	{"Quarantine", testQuarantine},
+	{"SourcePrices", testSourcePrices},
+	{"LongCoinIDs", testLongCoinIDs},
+	{"ConcurrentAccess", testConcurrentAccess},
+}
+
+func dec(t *testing.T, s string) model.Decimal {
+	t.Helper()
+	d, err := model.ParseDecimal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addPrices добавляет валюту coin и точки timestamp -> price
+func addPrices(t *testing.T, store sqlstore.StoreInterface, coin string, points map[int64]string) {
+	t.Helper()
+	ctx := context.Background()
+	must(t, store.Currency().AddCurrency(ctx, coin))
+	for ts, price := range points {
+		must(t, store.Currency().UpdatePrice(ctx, coin, dec(t, price), ts, "test"))
+	}
+}
+
+func assertPoint(t *testing.T, what string, got model.PricePoint, timestamp int64, price string) {
+	t.Helper()
+	if got.Timestamp != timestamp || (timestamp != 0 && got.Price.Cmp(dec(t, price)) != 0) {
+		t.Errorf("%s = {%d %s}, want {%d %s}", what, got.Timestamp, got.Price, timestamp, price)
+	}
+}
+
+func testCurrencyList(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	list, err := store.Currency().GetCurrencyList(ctx)
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("new store lists %v", list)
+	}
+	must(t, store.Currency().AddCurrency(ctx, "bitcoin"))
+	must(t, store.Currency().AddCurrency(ctx, "ethereum"))
+	// Повторное добавление - не ошибка
+	must(t, store.Currency().AddCurrency(ctx, "bitcoin"))
+	must(t, store.Currency().RemoveCurrency(ctx, "ethereum"))
+	// Удаление неотслеживаемой валюты - не ошибка
+	must(t, store.Currency().RemoveCurrency(ctx, "dogecoin"))
+
+	list, err = store.Currency().GetCurrencyList(ctx)
+	must(t, err)
+	sort.Strings(list)
+	if fmt.Sprint(list) != "[bitcoin]" {
+		t.Fatalf("GetCurrencyList = %v, want [bitcoin]", list)
+	}
+}
+
+func testUpdatePriceUntracked(t *testing.T, store sqlstore.StoreInterface) {
+	err := store.Currency().UpdatePrice(context.Background(), "bitcoin", dec(t, "1"), 100, "test")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("UpdatePrice of untracked coin = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testUpdatePriceUpsert(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	addPrices(t, store, "bitcoin", map[int64]string{100: "1.5"})
+	must(t, store.Currency().UpdatePrice(ctx, "bitcoin", dec(t, "2.5"), 100, "test"))
+
+	series, err := store.Currency().GetPriceSeries(ctx, "bitcoin", 0, 1000)
+	must(t, err)
+	if len(series) != 1 {
+		t.Fatalf("upsert left %d points, want 1", len(series))
+	}
+	assertPoint(t, "upserted point", series[0], 100, "2.5")
+}
+
+func testNearestPrice(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	currencies := store.Currency()
+
+	point, err := currencies.GetNearestPrice(ctx, "bitcoin", 100)
+	must(t, err)
+	assertPoint(t, "unknown coin", point, 0, "")
+	must(t, currencies.AddCurrency(ctx, "bitcoin"))
+	point, err = currencies.GetNearestPrice(ctx, "bitcoin", 100)
+	must(t, err)
+	assertPoint(t, "coin without prices", point, 0, "")
+
+	addPrices(t, store, "bitcoin", map[int64]string{100: "1", 200: "2", 400: "4"})
+	// Чужие точки не влияют на поиск
+	addPrices(t, store, "ethereum", map[int64]string{250: "99"})
+	tests := []struct {
+		at    int64
+		ts    int64
+		price string
+	}{
+		{0, 100, "1"},    // раньше всех точек
+		{100, 100, "1"},  // точное совпадение
+		{140, 100, "1"},  // ближе к предыдущей
+		{160, 200, "2"},  // ближе к следующей
+		{150, 100, "1"},  // равное расстояние - более ранняя
+		{300, 200, "2"},  // равное расстояние - более ранняя
+		{301, 400, "4"},  //
+		{1000, 400, "4"}, // позже всех точек
+	}
+	for _, tt := range tests {
+		point, err := currencies.GetNearestPrice(ctx, "bitcoin", tt.at)
+		must(t, err)
+		assertPoint(t, fmt.Sprintf("GetNearestPrice(%d)", tt.at), point, tt.ts, tt.price)
+		price, err := currencies.GetPrice(ctx, "bitcoin", tt.at)
+		must(t, err)
+		if price.Cmp(dec(t, tt.price)) != 0 {
+			t.Errorf("GetPrice(%d) = %s, want %s", tt.at, price, tt.price)
+		}
+	}
+}
+
+func testLastPriceAndSeries(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	currencies := store.Currency()
+	addPrices(t, store, "bitcoin", map[int64]string{100: "1", 200: "2", 300: "3"})
+
+	point, err := currencies.GetLastPrice(ctx, "bitcoin", 99)
+	must(t, err)
+	assertPoint(t, "GetLastPrice(99)", point, 0, "")
+	point, err = currencies.GetLastPrice(ctx, "bitcoin", 200)
+	must(t, err)
+	assertPoint(t, "GetLastPrice(200)", point, 200, "2")
+	point, err = currencies.GetLastPrice(ctx, "bitcoin", 299)
+	must(t, err)
+	assertPoint(t, "GetLastPrice(299)", point, 200, "2")
+
+	// Границы периода включаются, точки упорядочены по времени
+	series, err := currencies.GetPriceSeries(ctx, "bitcoin", 100, 200)
+	must(t, err)
+	if len(series) != 2 {
+		t.Fatalf("GetPriceSeries(100, 200) returned %d points, want 2", len(series))
+	}
+	assertPoint(t, "series[0]", series[0], 100, "1")
+	assertPoint(t, "series[1]", series[1], 200, "2")
+	series, err = currencies.GetPriceSeries(ctx, "bitcoin", 301, 400)
+	must(t, err)
+	if len(series) != 0 {
+		t.Fatalf("GetPriceSeries outside data returned %v", series)
+	}
+}
+
+func testDecimalPrecision(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	values := []string{"0.000000000000000001", "123456789012345678901234.5", "-0.5", "65000.12345678"}
+	must(t, store.Currency().AddCurrency(ctx, "bitcoin"))
+	for i, value := range values {
+		must(t, store.Currency().UpdatePrice(ctx, "bitcoin", dec(t, value), int64(i), "test"))
+	}
+	series, err := store.Currency().GetPriceSeries(ctx, "bitcoin", 0, int64(len(values)))
+	must(t, err)
+	if len(series) != len(values) {
+		t.Fatalf("got %d points, want %d", len(series), len(values))
+	}
+	for i, value := range values {
+		if series[i].Price.Cmp(dec(t, value)) != 0 {
+			t.Errorf("stored %s, read back %s", value, series[i].Price)
+		}
+	}
+}
+
+func testRemoveCascade(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	addPrices(t, store, "bitcoin", map[int64]string{100: "1"})
+	must(t, store.Market().SaveSnapshot(ctx, "bitcoin", model.MarketSnapshot{Timestamp: 100, Price: dec(t, "1")}))
+
+	// Цены и снимки удаляются вместе с валютой и не возвращаются при повторном добавлении
+	must(t, store.Currency().RemoveCurrency(ctx, "bitcoin"))
+	must(t, store.Currency().AddCurrency(ctx, "bitcoin"))
+	point, err := store.Currency().GetNearestPrice(ctx, "bitcoin", 100)
+	must(t, err)
+	assertPoint(t, "price after re-add", point, 0, "")
+	snapshots, err := store.Market().GetSnapshots(ctx, "bitcoin", 0, 1000)
+	must(t, err)
+	if len(snapshots) != 0 {
+		t.Fatalf("snapshots survived removal: %v", snapshots)
+	}
+}
+
+func testSnapshots(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	market := store.Market()
+	marketCap := dec(t, "1300000000000.5")
+
+	// Снимок неотслеживаемой валюты игнорируется
+	must(t, market.SaveSnapshot(ctx, "bitcoin", model.MarketSnapshot{Timestamp: 50, Price: dec(t, "1")}))
+	must(t, store.Currency().AddCurrency(ctx, "bitcoin"))
+	must(t, market.SaveSnapshot(ctx, "bitcoin", model.MarketSnapshot{Timestamp: 200, Price: dec(t, "2")}))
+	must(t, market.SaveSnapshot(ctx, "bitcoin", model.MarketSnapshot{Timestamp: 100, Price: dec(t, "1"), MarketCap: &marketCap}))
+	// Повтор момента перезаписывает снимок
+	must(t, market.SaveSnapshot(ctx, "bitcoin", model.MarketSnapshot{Timestamp: 200, Price: dec(t, "3")}))
+
+	snapshots, err := market.GetSnapshots(ctx, "bitcoin", 0, 1000)
+	must(t, err)
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(snapshots))
+	}
+	if snapshots[0].Timestamp != 100 || snapshots[0].MarketCap == nil || snapshots[0].MarketCap.Cmp(marketCap) != 0 ||
+		snapshots[0].TotalVolume != nil {
+		t.Errorf("snapshot[0] = %+v", snapshots[0])
+	}
+	if snapshots[1].Timestamp != 200 || snapshots[1].Price.Cmp(dec(t, "3")) != 0 {
+		t.Errorf("snapshot[1] = %+v, want upserted price 3", snapshots[1])
+	}
+}
+
+func testPortfolios(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	portfolios := store.Portfolio()
+
+	if _, err := portfolios.GetPortfolio(ctx, 12345); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetPortfolio of missing id = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := portfolios.AddPosition(ctx, 12345, model.Position{CoinID: "bitcoin"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("AddPosition to missing portfolio = %v, want sql.ErrNoRows", err)
+	}
+
+	created, err := portfolios.CreatePortfolio(ctx, "main")
+	must(t, err)
+	if created.ID == 0 || created.Name != "main" {
+		t.Fatalf("CreatePortfolio = %+v", created)
+	}
+	later, err := portfolios.AddPosition(ctx, created.ID, model.Position{
+		CoinID: "ethereum", Quantity: dec(t, "2"), CostBasis: dec(t, "6000"), AcquiredAt: 200,
+	})
+	must(t, err)
+	earlier, err := portfolios.AddPosition(ctx, created.ID, model.Position{
+		CoinID: "bitcoin", Quantity: dec(t, "0.00012345"), CostBasis: dec(t, "7.5"), AcquiredAt: 100,
+	})
+	must(t, err)
+	if later.ID == 0 || earlier.ID == 0 || later.ID == earlier.ID {
+		t.Fatalf("position ids %d, %d", later.ID, earlier.ID)
+	}
+
+	got, err := portfolios.GetPortfolio(ctx, created.ID)
+	must(t, err)
+	if got.Name != "main" || len(got.Positions) != 2 {
+		t.Fatalf("GetPortfolio = %+v", got)
+	}
+	// Позиции упорядочены по времени покупки
+	if got.Positions[0].ID != earlier.ID || got.Positions[1].ID != later.ID {
+		t.Errorf("positions out of order: %+v", got.Positions)
+	}
+	if got.Positions[0].Quantity.Cmp(dec(t, "0.00012345")) != 0 || got.Positions[0].CostBasis.Cmp(dec(t, "7.5")) != 0 {
+		t.Errorf("position[0] = %+v", got.Positions[0])
+	}
+}
+
+func testQuarantine(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	quarantine := store.Quarantine()
+
+	if _, err := quarantine.GetQuarantined(ctx, 12345); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetQuarantined of missing id = %v, want sql.ErrNoRows", err)
+	}
+	// Валюта в карантине не обязана отслеживаться
+	second, err := quarantine.AddQuarantined(ctx, model.QuarantinedPrice{
+		CoinID: "bitcoin", Price: dec(t, "-1"), Timestamp: 200, Reason: "non_positive", CreatedAt: 1,
+	})
+	must(t, err)
+	first, err := quarantine.AddQuarantined(ctx, model.QuarantinedPrice{
+		CoinID: "bitcoin", Price: dec(t, "1000000"), Timestamp: 100, Reason: "jump", Detail: "x1000", CreatedAt: 2,
+	})
+	must(t, err)
+	_, err = quarantine.AddQuarantined(ctx, model.QuarantinedPrice{
+		CoinID: "ethereum", Price: dec(t, "0"), Timestamp: 150, Reason: "non_positive", CreatedAt: 3,
+	})
+	must(t, err)
+
+	all, err := quarantine.ListQuarantined(ctx, "")
+	must(t, err)
+	if len(all) != 3 {
+		t.Fatalf("ListQuarantined(all) returned %d records, want 3", len(all))
+	}
+	coin, err := quarantine.ListQuarantined(ctx, "bitcoin")
+	must(t, err)
+	if len(coin) != 2 || coin[0].ID != first.ID || coin[1].ID != second.ID {
+		t.Fatalf("ListQuarantined(bitcoin) = %+v, want ordered by timestamp", coin)
+	}
+
+	got, err := quarantine.GetQuarantined(ctx, first.ID)
+	must(t, err)
+	if got.Reason != "jump" || got.Detail != "x1000" || got.Price.Cmp(dec(t, "1000000")) != 0 {
+		t.Errorf("GetQuarantined = %+v", got)
+	}
+	must(t, quarantine.DeleteQuarantined(ctx, first.ID))
+	if _, err := quarantine.GetQuarantined(ctx, first.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetQuarantined after delete = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testSourcePrices(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	sources := store.Source()
+	must(t, sources.SaveSourcePrices(ctx, nil))
+	must(t, sources.SaveSourcePrices(ctx, []model.SourcePrice{
+		{CoinID: "bitcoin", Source: "coincap", Price: dec(t, "101"), Consensus: dec(t, "100.5"),
+			DeviationPercent: dec(t, "0.4975"), Timestamp: 100},
+		{CoinID: "bitcoin", Source: "coingecko", Price: dec(t, "100"), Consensus: dec(t, "100.5"),
+			DeviationPercent: dec(t, "0.4975"), Flagged: true, Timestamp: 100},
+		{CoinID: "ethereum", Source: "coingecko", Price: dec(t, "3000"), Consensus: dec(t, "3000"), Timestamp: 100},
+	}))
+	must(t, sources.SaveSourcePrices(ctx, []model.SourcePrice{
+		{CoinID: "bitcoin", Source: "coingecko", Price: dec(t, "99"), Consensus: dec(t, "99"), Timestamp: 50},
+	}))
+
+	got, err := sources.GetSourcePrices(ctx, "bitcoin", 0, 100)
+	must(t, err)
+	if len(got) != 3 {
+		t.Fatalf("GetSourcePrices returned %d records, want 3", len(got))
+	}
+	// Порядок - по времени, затем по имени провайдера
+	if got[0].Timestamp != 50 || got[1].Source != "coincap" || got[2].Source != "coingecko" {
+		t.Fatalf("GetSourcePrices order: %+v", got)
+	}
+	if !got[2].Flagged || got[1].Flagged || got[2].DeviationPercent.Cmp(dec(t, "0.4975")) != 0 {
+		t.Errorf("flags or deviation lost: %+v", got)
+	}
+	got, err = sources.GetSourcePrices(ctx, "bitcoin", 51, 99)
+	must(t, err)
+	if len(got) != 0 {
+		t.Errorf("GetSourcePrices(51, 99) = %+v, want empty", got)
+	}
+}
+
+// testLongCoinIDs проверяет id валют CoinGecko длиннее 10 символов во всех таблицах
+func testLongCoinIDs(t *testing.T, store sqlstore.StoreInterface) {
+	ctx := context.Background()
+	for _, coin := range []string{"avalanche-2", "matic-network", "wrapped-steth-on-some-long-chain-name"} {
+		addPrices(t, store, coin, map[int64]string{100: "1"})
+		point, err := store.Currency().GetNearestPrice(ctx, coin, 100)
+		must(t, err)
+		assertPoint(t, coin, point, 100, "1")
+
+		portfolio, err := store.Portfolio().CreatePortfolio(ctx, coin)
+		must(t, err)
+		_, err = store.Portfolio().AddPosition(ctx, portfolio.ID, model.Position{
+			CoinID: coin, Quantity: dec(t, "1"), CostBasis: dec(t, "1"), AcquiredAt: 100,
+		})
+		must(t, err)
+		_, err = store.Quarantine().AddQuarantined(ctx, model.QuarantinedPrice{
+			CoinID: coin, Price: dec(t, "1"), Timestamp: 100, Reason: "jump",
+		})
+		must(t, err)
+		must(t, store.Source().SaveSourcePrices(ctx, []model.SourcePrice{
+			{CoinID: coin, Source: "coingecko", Price: dec(t, "1"), Consensus: dec(t, "1"), Timestamp: 100},
+		}))
+	}
+}
+
+// testConcurrentAccess пишет и читает из нескольких горутин; с -race проверяет
+// потокобезопасность, без него - что параллельные записи не теряются
+func testConcurrentAccess(t *testing.T, store sqlstore.StoreInterface) {
+	const (
+		writers = 8
+		points  = 25
+	)
+	ctx := context.Background()
+	must(t, store.Currency().AddCurrency(ctx, "bitcoin"))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*points*2)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < points; i++ {
+				ts := int64(w*points + i)
+				if err := store.Currency().UpdatePrice(ctx, "bitcoin", model.NewDecimal(ts, 2), ts, "test"); err != nil {
+					errs <- err
+				}
+				if _, err := store.Currency().GetNearestPrice(ctx, "bitcoin", ts); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	series, err := store.Currency().GetPriceSeries(ctx, "bitcoin", 0, writers*points)
+	must(t, err)
+	if len(series) != writers*points {
+		t.Fatalf("got %d points after concurrent writes, want %d", len(series), writers*points)
+	}
+}
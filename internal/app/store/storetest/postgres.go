@@ -0,0 +1,78 @@
+// Package storetest - общие помощники тестов хранилищ: изолированная схема Postgres
+// для интеграционных тестов и набор проверок, которые проходит каждая реализация StoreInterface.
+package storetest
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/lib/pq"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Переменные окружения с DSN тестовых баз. Без них Postgres-тесты пропускаются.
+const (
+	PostgresDSNEnv  = "TEST_POSTGRES_DSN"  // Обычный Postgres
+	TimescaleDSNEnv = "TEST_TIMESCALE_DSN" // Postgres с расширением timescaledb
+)
+
+// OpenPostgres подключается к базе из переменной env и создает для теста отдельную схему,
+// которая удаляется после теста. Существующие данные в базе не затрагиваются.
+// Если переменная не задана, тест пропускается.
+func OpenPostgres(tb testing.TB, env string) *sql.DB {
+	tb.Helper()
+	dsn := os.Getenv(env)
+	if dsn == "" {
+		tb.Skipf("%s is not set", env)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer admin.Close()
+	schema := fmt.Sprintf("test_%d", rand.Uint64())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		tb.Fatalf("create test schema: %v", err)
+	}
+
+	// public остается в пути поиска: там живут функции расширений
+	db, err := sql.Open("postgres", withSearchPath(dsn, schema+",public"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		_ = db.Close()
+		cleanup, err := sql.Open("postgres", dsn)
+		if err != nil {
+			tb.Logf("drop test schema %s: %v", schema, err)
+			return
+		}
+		defer cleanup.Close()
+		// Непрерывные агрегаты нельзя удалить каскадом вместе со схемой
+		for _, view := range []string{"currency_candles_1d", "currency_candles_1h"} {
+			_, _ = cleanup.Exec("DROP MATERIALIZED VIEW IF EXISTS " + schema + "." + view)
+		}
+		if _, err := cleanup.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			tb.Logf("drop test schema %s: %v", schema, err)
+		}
+	})
+	return db
+}
+
+// withSearchPath добавляет search_path к DSN в виде URL или key=value
+func withSearchPath(dsn, path string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			q := u.Query()
+			q.Set("search_path", path)
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path='" + path + "'"
+}
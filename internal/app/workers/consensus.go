@@ -0,0 +1,162 @@
+package worker
+
+import (
+	coingecko "cryptoObserver/internal/app/coingeko"
+	"cryptoObserver/internal/app/model"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Способы свертки цен провайдеров в одну
+const (
+	ConsensusMedian      = "median"
+	ConsensusTrimmedMean = "trimmed_mean"
+)
+
+// Source - именованный провайдер цен
+type Source struct {
+	Name   string
+	Client coingecko.CryptoInterface
+}
+
+// consensus - настройки опроса нескольких провайдеров
+type consensus struct {
+	sources       []Source
+	method        string  // median или trimmed_mean
+	maxDivergence float64 // Отклонение от консенсуса, после которого провайдер помечается, %
+}
+
+// sourceQuote - ответ одного провайдера
+type sourceQuote struct {
+	source string
+	price  *coingecko.CryptoPriceResponse
+	err    error
+}
+
+// SetConsensus включает режим консенсуса: каждая валюта запрашивается у всех
+// sources, а в историю пишется медиана или усеченное среднее их цен.
+// Должен вызываться до Start.
+func (wp *WorkerPool) SetConsensus(sources []Source, method string, maxDivergence float64) {
+	wp.consensus = &consensus{sources: sources, method: method, maxDivergence: maxDivergence}
+}
+
+// fetchConsensus опрашивает провайдеров параллельно и сводит их цены в одну.
+// Рыночные данные берутся из первого ответившего по порядку провайдера,
+// цена в них заменяется консенсусной. Цены провайдеров сохраняются вместе с отклонением.
+// Если большинство ответивших провайдеров не сходится, возвращается ошибка и точка пропускается.
+func (wp *WorkerPool) fetchConsensus(currencyID string, timestamp int64) (*coingecko.CryptoPriceResponse, error) {
+	c := wp.consensus
+	quotes := make([]sourceQuote, len(c.sources))
+	var wg sync.WaitGroup
+	for i, source := range c.sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			price, err := source.Client.GetCryptoPrice(wp.ctx, currencyID)
+			quotes[i] = sourceQuote{source: source.Name, price: price, err: err}
+		}(i, source)
+	}
+	wg.Wait()
+
+	var base *coingecko.CryptoPriceResponse
+	var prices []model.Decimal
+	var failed []string
+	for _, quote := range quotes {
+		if quote.err != nil {
+			wp.log.Warnf("Source %s failed to fetch %s: %v", quote.source, currencyID, quote.err)
+			failed = append(failed, quote.source)
+			continue
+		}
+		if base == nil {
+			base = quote.price
+		}
+		prices = append(prices, quote.price.CurrentPrice)
+	}
+	if base == nil {
+		return nil, fmt.Errorf("all sources failed: %s", strings.Join(failed, ", "))
+	}
+
+	// Провайдеры, разошедшиеся с первой сверткой, в итоговую цену не входят:
+	// при двух провайдерах медиана и усеченное среднее - это среднее, и выброс
+	// сдвинул бы его наполовину. Без согласного большинства точка не пишется.
+	agreed := aggregate(prices, c.method)
+	var accepted []model.Decimal
+	for _, price := range prices {
+		if !c.diverges(price, agreed) {
+			accepted = append(accepted, price)
+		}
+	}
+	majority := 2*len(accepted) > len(prices)
+	if majority && len(accepted) < len(prices) {
+		agreed = aggregate(accepted, c.method)
+	}
+
+	records := make([]model.SourcePrice, 0, len(prices))
+	var flagged []string
+	for _, quote := range quotes {
+		if quote.err != nil {
+			continue
+		}
+		record := model.SourcePrice{
+			CoinID:    currencyID,
+			Source:    quote.source,
+			Price:     quote.price.CurrentPrice,
+			Consensus: agreed,
+			Timestamp: timestamp,
+		}
+		if deviation := model.PercentOf(quote.price.CurrentPrice.Sub(agreed).Abs(), agreed); deviation != nil {
+			record.DeviationPercent = *deviation
+		}
+		record.Flagged = c.diverges(quote.price.CurrentPrice, agreed)
+		if record.Flagged {
+			flagged = append(flagged, fmt.Sprintf("%s=%s (%s%%)", quote.source, record.Price, record.DeviationPercent))
+		}
+		records = append(records, record)
+	}
+	if len(flagged) > 0 {
+		wp.log.Warnf("Sources diverge from consensus %s of %s by more than %.2f%%: %s",
+			agreed, currencyID, c.maxDivergence, strings.Join(flagged, ", "))
+	}
+	if err := wp.db.Source().SaveSourcePrices(wp.ctx, records); err != nil {
+		wp.log.Errorf("Failed to save source prices of %s: %v", currencyID, err)
+	}
+	if !majority {
+		return nil, fmt.Errorf("no majority of sources agree within %.2f%%: %d of %d", c.maxDivergence, len(accepted), len(prices))
+	}
+
+	result := *base
+	result.CurrentPrice = agreed
+	return &result, nil
+}
+
+// diverges проверяет, отклоняется ли цена от консенсуса больше чем на maxDivergence
+func (c *consensus) diverges(price, agreed model.Decimal) bool {
+	deviation := model.PercentOf(price.Sub(agreed).Abs(), agreed)
+	return deviation != nil && deviation.Float64() > c.maxDivergence
+}
+
+// aggregate сводит цены провайдеров медианой или усеченным средним.
+// Усеченное среднее отбрасывает минимум и максимум, если цен хотя бы три.
+func aggregate(prices []model.Decimal, method string) model.Decimal {
+	sorted := append([]model.Decimal(nil), prices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	if method == ConsensusTrimmedMean {
+		if len(sorted) >= 3 {
+			sorted = sorted[1 : len(sorted)-1]
+		}
+		var sum model.Decimal
+		for _, price := range sorted {
+			sum = sum.Add(price)
+		}
+		return sum.Div(model.NewDecimal(int64(len(sorted)), 0), model.DefaultScale)
+	}
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return sorted[middle-1].Add(sorted[middle]).Div(model.NewDecimal(2, 0), model.DefaultScale)
+}
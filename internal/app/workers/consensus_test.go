@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	coingecko "cryptoObserver/internal/app/coingeko"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/memstore"
+	"errors"
+	"testing"
+	"time"
+)
+
+// quoteClient отвечает заданной ценой или ошибкой
+type quoteClient struct {
+	price string
+	err   error
+}
+
+func (c quoteClient) GetCryptoPrice(ctx context.Context, id string) (*coingecko.CryptoPriceResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	price, err := model.ParseDecimal(c.price)
+	if err != nil {
+		return nil, err
+	}
+	return &coingecko.CryptoPriceResponse{ID: id, CurrentPrice: price}, nil
+}
+
+func TestFetchConsensus(t *testing.T) {
+	down := errors.New("unavailable")
+	tests := []struct {
+		name    string
+		method  string
+		quotes  []quoteClient
+		want    string // Пусто - точка пропускается
+		flagged int
+	}{
+		{"agree", ConsensusMedian, []quoteClient{{price: "100"}, {price: "101"}}, "100.5", 0},
+		// Два провайдера расходятся - большинства нет
+		{"two disagree", ConsensusMedian, []quoteClient{{price: "100"}, {price: "100000"}}, "", 2},
+		{"two disagree trimmed", ConsensusTrimmedMean, []quoteClient{{price: "100"}, {price: "100000"}}, "", 2},
+		// Выброс не входит в итоговую цену
+		{"outlier median", ConsensusMedian, []quoteClient{{price: "100"}, {price: "101"}, {price: "100000"}}, "100.5", 1},
+		{"outlier trimmed", ConsensusTrimmedMean, []quoteClient{{price: "100"}, {price: "101"}, {price: "102"}, {price: "100000"}}, "101", 1},
+		{"one answered", ConsensusMedian, []quoteClient{{price: "100"}, {err: down}}, "100", 0},
+		{"half agree", ConsensusMedian, []quoteClient{{price: "100"}, {price: "100.5"}, {price: "300"}, {price: "100000"}}, "", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memstore.New()
+			sources := make([]Source, len(tt.quotes))
+			for i, quote := range tt.quotes {
+				sources[i] = Source{Name: string(rune('a' + i)), Client: quote}
+			}
+			pool := NewWorkerPool(context.Background(), sources[0], store, 1, time.Minute, time.Minute, newTestLogger())
+			pool.SetConsensus(sources, tt.method, 2)
+
+			price, err := pool.fetchConsensus("bitcoin", 100)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("consensus %s written without a majority", price.CurrentPrice)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if want, _ := model.ParseDecimal(tt.want); price.CurrentPrice.Cmp(want) != 0 {
+				t.Fatalf("consensus = %s, want %s", price.CurrentPrice, tt.want)
+			}
+
+			// Цены провайдеров сохраняются и без консенсуса, чтобы расхождение было видно
+			records, err := store.Source().GetSourcePrices(context.Background(), "bitcoin", 0, 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			flagged := 0
+			for _, record := range records {
+				if record.Flagged {
+					flagged++
+				}
+			}
+			if flagged != tt.flagged {
+				t.Fatalf("%d sources flagged, want %d: %+v", flagged, tt.flagged, records)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package worker
+
+import (
+	coingecko "cryptoObserver/internal/app/coingeko"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// failover - переключение валюты на резервных провайдеров, пока основной отвечает ошибками
+type failover struct {
+	sources       []Source      // Первый - основной, остальные - резервные по порядку
+	threshold     int           // Ошибок основного подряд до переключения
+	probeInterval time.Duration // Как часто проверять основной после переключения
+
+	mu    sync.Mutex
+	coins map[string]*failoverState
+}
+
+// failoverState - состояние одной валюты
+type failoverState struct {
+	failures   int       // Ошибок основного провайдера подряд
+	failedOver bool      // Валюта переключена на резервных провайдеров
+	probeAt    time.Time // Когда снова запросить основного провайдера
+}
+
+// SetFailover включает переключение на резервных провайдеров: если sources[0] отвечает
+// ошибкой threshold раз подряд, валюта запрашивается у остальных по порядку,
+// а основной проверяется раз в probeInterval и снова используется, как только ответит.
+// Должен вызываться до Start.
+func (wp *WorkerPool) SetFailover(sources []Source, threshold int, probeInterval time.Duration) {
+	wp.failover = &failover{
+		sources:       sources,
+		threshold:     threshold,
+		probeInterval: probeInterval,
+		coins:         make(map[string]*failoverState),
+	}
+}
+
+// fetchFailover запрашивает цену у основного провайдера или, если валюта
+// переключена, у резервных. Возвращает цену и имя ответившего провайдера.
+func (wp *WorkerPool) fetchFailover(currencyID string) (*coingecko.CryptoPriceResponse, string, error) {
+	f := wp.failover
+	primary := f.sources[0]
+
+	if f.shouldProbe(currencyID, time.Now()) {
+		price, err := primary.Client.GetCryptoPrice(wp.ctx, currencyID)
+		if err == nil {
+			if f.recovered(currencyID) {
+				wp.log.Infof("Source %s recovered for %s, switching back", primary.Name, currencyID)
+			}
+			return price, primary.Name, nil
+		}
+		failedOver, switched := f.failed(currencyID, time.Now())
+		if !failedOver {
+			return nil, "", err
+		}
+		if switched {
+			wp.log.Warnf("Source %s failed %d times in a row for %s, failing over: %v", primary.Name, f.threshold, currencyID, err)
+		} else {
+			wp.log.Warnf("Source %s is still failing for %s: %v", primary.Name, currencyID, err)
+		}
+	}
+
+	var lastErr error
+	for _, source := range f.sources[1:] {
+		price, err := source.Client.GetCryptoPrice(wp.ctx, currencyID)
+		if err == nil {
+			return price, source.Name, nil
+		}
+		wp.log.Warnf("Source %s failed to fetch %s: %v", source.Name, currencyID, err)
+		lastErr = err
+	}
+	return nil, "", fmt.Errorf("all fallback sources failed: %w", lastErr)
+}
+
+// shouldProbe сообщает, нужно ли запрашивать основного провайдера
+func (f *failover) shouldProbe(coin string, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state, exists := f.coins[coin]
+	return !exists || !state.failedOver || !now.Before(state.probeAt)
+}
+
+// recovered сбрасывает счетчик ошибок и возвращает true, если валюта была переключена
+func (f *failover) recovered(coin string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state, exists := f.coins[coin]
+	if !exists {
+		return false
+	}
+	delete(f.coins, coin)
+	return state.failedOver
+}
+
+// failed учитывает ошибку основного провайдера. Возвращает, переключена ли валюта,
+// и произошло ли переключение именно сейчас.
+func (f *failover) failed(coin string, now time.Time) (failedOver, switched bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state, exists := f.coins[coin]
+	if !exists {
+		state = &failoverState{}
+		f.coins[coin] = state
+	}
+	state.failures++
+	if !state.failedOver && state.failures >= f.threshold {
+		state.failedOver = true
+		switched = true
+	}
+	if state.failedOver {
+		state.probeAt = now.Add(f.probeInterval)
+	}
+	return state.failedOver, switched
+}
+
+// forget удаляет состояние валюты, снятой с отслеживания
+func (f *failover) forget(coin string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.coins, coin)
+}
@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	coingecko "cryptoObserver/internal/app/coingeko"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/memstore"
+	"cryptoObserver/internal/app/store/sqlstore"
+	"errors"
+	"testing"
+	"time"
+)
+
+// switchClient отвечает ценой или, пока down, ошибкой и считает запросы
+type switchClient struct {
+	price string
+	down  bool
+	calls int
+}
+
+func (c *switchClient) GetCryptoPrice(ctx context.Context, id string) (*coingecko.CryptoPriceResponse, error) {
+	c.calls++
+	if c.down {
+		return nil, errors.New("unavailable")
+	}
+	return quoteClient{price: c.price}.GetCryptoPrice(ctx, id)
+}
+
+// sourceStore запоминает провайдеров записанных цен
+type sourceStore struct {
+	sqlstore.StoreInterface
+	currencies *sourceCurrencies
+}
+
+func (s sourceStore) Currency() sqlstore.CurrencyInterface {
+	return s.currencies
+}
+
+type sourceCurrencies struct {
+	sqlstore.CurrencyInterface
+	sources []string
+}
+
+func (c *sourceCurrencies) UpdatePrice(ctx context.Context, coin string, price model.Decimal, timestamp int64, source string) error {
+	c.sources = append(c.sources, source)
+	return c.CurrencyInterface.UpdatePrice(ctx, coin, price, timestamp, source)
+}
+
+func TestFailover(t *testing.T) {
+	// step - один опрос валюты
+	type step struct {
+		primaryDown bool
+		probeDue    bool   // Интервал проверки основного провайдера истек
+		want        string // Провайдер записанной цены, пусто - цена не записана
+	}
+	const threshold = 3
+	tests := []struct {
+		name           string
+		steps          []step
+		primaryCalls   int
+		secondaryCalls int
+	}{
+		{
+			name: "below threshold stays on primary",
+			steps: []step{
+				{primaryDown: true}, {primaryDown: true},
+				{want: "primary"},
+			},
+			primaryCalls: 3,
+		},
+		{
+			name: "threshold fails over",
+			steps: []step{
+				{primaryDown: true}, {primaryDown: true},
+				{primaryDown: true, want: "secondary"},
+				// До истечения интервала основной не запрашивается, даже если уже ответил бы
+				{want: "secondary"},
+			},
+			primaryCalls:   3,
+			secondaryCalls: 2,
+		},
+		{
+			name: "probe switches back",
+			steps: []step{
+				{primaryDown: true}, {primaryDown: true},
+				{primaryDown: true, want: "secondary"},
+				{probeDue: true, want: "primary"},
+				{want: "primary"},
+			},
+			primaryCalls:   5,
+			secondaryCalls: 1,
+		},
+		{
+			name: "failed probe stays on secondary",
+			steps: []step{
+				{primaryDown: true}, {primaryDown: true},
+				{primaryDown: true, want: "secondary"},
+				{primaryDown: true, probeDue: true, want: "secondary"},
+				{want: "secondary"},
+			},
+			primaryCalls:   4,
+			secondaryCalls: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := memstore.New()
+			if err := mem.Currency().AddCurrency(context.Background(), "bitcoin"); err != nil {
+				t.Fatal(err)
+			}
+			store := sourceStore{StoreInterface: mem, currencies: &sourceCurrencies{CurrencyInterface: mem.Currency()}}
+			primary := &switchClient{price: "100"}
+			secondary := &switchClient{price: "101"}
+			sources := []Source{{Name: "primary", Client: primary}, {Name: "secondary", Client: secondary}}
+			pool := NewWorkerPool(context.Background(), sources[0], store, 1, time.Minute, time.Minute, newTestLogger())
+			pool.SetFailover(sources, threshold, time.Hour)
+			pool.mu.Lock()
+			pool.addCurrency("bitcoin")
+			pool.mu.Unlock()
+
+			for i, step := range tt.steps {
+				primary.down = step.primaryDown
+				if state, ok := pool.failover.coins["bitcoin"]; ok && step.probeDue {
+					state.probeAt = time.Now().Add(-time.Second)
+				}
+				written := len(store.currencies.sources)
+				pool.processCurrency("bitcoin")
+
+				got := ""
+				if len(store.currencies.sources) > written {
+					got = store.currencies.sources[written]
+				}
+				if got != step.want {
+					t.Fatalf("step %d: stored source = %q, want %q", i, got, step.want)
+				}
+			}
+			if primary.calls != tt.primaryCalls || secondary.calls != tt.secondaryCalls {
+				t.Fatalf("calls: primary %d, secondary %d, want %d and %d",
+					primary.calls, secondary.calls, tt.primaryCalls, tt.secondaryCalls)
+			}
+		})
+	}
+}
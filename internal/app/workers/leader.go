@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"sync/atomic"
+	"time"
+)
+
+// Ключ advisory-блокировки, за которую соревнуются реплики
+const leaderLockKey int64 = 0x63727970746f // "crypto"
+
+// LeaderElector выбирает единственную реплику, которая опрашивает API.
+// Лидер держит сессионную advisory-блокировку Postgres на выделенном соединении;
+// если реплика падает или теряет соединение, Postgres снимает блокировку,
+// и ее забирает другая реплика на следующей попытке.
+type LeaderElector struct {
+	db       *sql.DB
+	interval time.Duration
+	log      *logrus.Logger
+	conn     *sql.Conn // Соединение, на котором удерживается блокировка
+	leader   atomic.Bool
+}
+
+func NewLeaderElector(db *sql.DB, interval time.Duration, log *logrus.Logger) *LeaderElector {
+	return &LeaderElector{
+		db:       db,
+		interval: interval,
+		log:      log,
+	}
+}
+
+// Owns возвращает true для всех валют, пока реплика - лидер
+func (e *LeaderElector) Owns(string) bool {
+	return e.leader.Load()
+}
+
+// Run периодически пытается захватить лидерство и проверяет, что оно не потеряно.
+// При отмене контекста блокировка освобождается.
+func (e *LeaderElector) Run(ctx context.Context) {
+	defer e.release()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.elect(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Одна попытка выборов
+func (e *LeaderElector) elect(ctx context.Context) {
+	if e.conn == nil {
+		conn, err := e.db.Conn(ctx)
+		if err != nil {
+			e.log.Warnf("Leader election: failed to get DB connection: %v", err)
+			return
+		}
+		e.conn = conn
+	}
+
+	if e.leader.Load() {
+		// Блокировка живет, пока живо соединение
+		if err := e.conn.PingContext(ctx); err != nil {
+			e.log.Warnf("Leader election: lost connection holding the lock: %v", err)
+			e.dropConn()
+		}
+		return
+	}
+
+	var acquired bool
+	err := e.conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", leaderLockKey).Scan(&acquired)
+	if err != nil {
+		e.log.Warnf("Leader election: failed to acquire lock: %v", err)
+		e.dropConn()
+		return
+	}
+	if acquired {
+		e.leader.Store(true)
+		e.log.Info("Leader election: this replica is now the leader")
+	}
+}
+
+// Закрываем соединение; если реплика была лидером, она им больше не является
+func (e *LeaderElector) dropConn() {
+	if e.leader.Swap(false) {
+		e.log.Warn("Leader election: leadership lost")
+	}
+	if e.conn != nil {
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+}
+
+// Освобождаем блокировку при остановке, чтобы другая реплика подхватила работу сразу
+func (e *LeaderElector) release() {
+	if e.conn == nil {
+		return
+	}
+	if e.leader.Load() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := e.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", leaderLockKey); err != nil {
+			e.log.Warnf("Leader election: failed to release lock: %v", err)
+		}
+	}
+	e.dropConn()
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"encoding/json"
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	// Канал, в который триггер на таблице currencies шлет уведомления
+	currenciesChannel = "currencies_changed"
+
+	listenerMinReconnect  = 1 * time.Second
+	listenerMaxReconnect  = 30 * time.Second
+	listenerCheckInterval = 90 * time.Second
+)
+
+// currencyEvent - полезная нагрузка уведомления из notify_currencies_changed()
+type currencyEvent struct {
+	Op     string `json:"op"` // add или remove
+	Symbol string `json:"symbol"`
+}
+
+// CurrencyCache - кеш хранилища, привязанный к строкам currencies, например
+// id валют в буфере записи цен. Устаревает, когда валюту удаляют или пересоздают.
+type CurrencyCache interface {
+	ForgetCurrency(symbol string)
+}
+
+// SetCurrencyCache задает кеш, который пул сбрасывает при удалении и добавлении валют,
+// в том числе по уведомлениям от других реплик. Должен вызываться до Start.
+func (wp *WorkerPool) SetCurrencyCache(cache CurrencyCache) {
+	wp.currencyCache = cache
+}
+
+// Listen подписывает пул на изменения таблицы currencies через LISTEN/NOTIFY,
+// чтобы валюты, добавленные или удаленные на другой реплике, подхватывались сразу.
+// Разрывы соединения обрабатываются pq.Listener, после переподключения пул
+// сверяется с БД, так как уведомления за время простоя теряются.
+func (wp *WorkerPool) Listen(connString string) error {
+	listener := pq.NewListener(connString, listenerMinReconnect, listenerMaxReconnect, wp.logListenerEvent)
+	if err := listener.Listen(currenciesChannel); err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	wp.wg.Add(1)
+	go wp.listenLoop(listener)
+	return nil
+}
+
+// Цикл обработки уведомлений
+func (wp *WorkerPool) listenLoop(listener *pq.Listener) {
+	defer wp.wg.Done()
+	defer listener.Close()
+
+	check := time.NewTicker(listenerCheckInterval)
+	defer check.Stop()
+
+	for {
+		select {
+		case <-wp.ctx.Done():
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				// Соединение восстановлено, часть уведомлений могла потеряться
+				wp.Refresh()
+				continue
+			}
+			wp.handleCurrencyEvent(n.Extra)
+		case <-check.C:
+			// Проверяем, что соединение живо; при ошибке pq переподключится сам
+			go func() {
+				if err := listener.Ping(); err != nil {
+					wp.log.Warnf("Currency listener ping failed: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+// Применяем уведомление к набору отслеживаемых валют
+func (wp *WorkerPool) handleCurrencyEvent(payload string) {
+	var event currencyEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		wp.log.Errorf("Failed to decode currency notification %q: %v", payload, err)
+		return
+	}
+
+	wp.mu.Lock()
+	switch event.Op {
+	case "add":
+		// Валюту могли удалить и добавить заново с новым id, пока уведомление об удалении терялось
+		wp.forgetCached(event.Symbol)
+		wp.addCurrency(event.Symbol)
+	case "remove":
+		wp.removeCurrency(event.Symbol)
+	default:
+		wp.log.Warnf("Unknown currency notification op: %s", event.Op)
+	}
+	wp.mu.Unlock()
+	wp.notify()
+}
+
+// Логируем события соединения слушателя
+func (wp *WorkerPool) logListenerEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventConnected:
+		wp.log.Info("Currency listener connected")
+	case pq.ListenerEventDisconnected:
+		wp.log.Warnf("Currency listener disconnected: %v", err)
+	case pq.ListenerEventReconnected:
+		wp.log.Info("Currency listener reconnected")
+	case pq.ListenerEventConnectionAttemptFailed:
+		wp.log.Warnf("Currency listener connection attempt failed: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Сколько интервалов heartbeat реплика считается живой без обновления
+const memberTTLIntervals = 3
+
+// ShardMembership распределяет валюты между живыми репликами.
+// Каждая реплика пишет heartbeat в worker_members, читает список живых участников
+// и строит по нему кольцо консистентного хеширования; валюта принадлежит
+// реплике, на которую она попала в кольце. Состав пересчитывается на каждом
+// heartbeat, поэтому при входе или падении реплики шарды перераспределяются сами.
+type ShardMembership struct {
+	db       *sql.DB
+	id       string
+	interval time.Duration
+	ttl      time.Duration
+	log      *logrus.Logger
+
+	mu       sync.RWMutex
+	ring     *hashRing
+	members  []string
+	lastSeen time.Time // Последний успешный heartbeat
+}
+
+func NewShardMembership(db *sql.DB, id string, interval time.Duration, log *logrus.Logger) *ShardMembership {
+	return &ShardMembership{
+		db:       db,
+		id:       id,
+		interval: interval,
+		ttl:      memberTTLIntervals * interval,
+		log:      log,
+	}
+}
+
+// Owns проверяет, попала ли валюта в шард этой реплики
+func (m *ShardMembership) Owns(currencyID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Без связи с БД дольше TTL остальные реплики уже забрали наш шард
+	if m.ring == nil || time.Since(m.lastSeen) > m.ttl {
+		return false
+	}
+	return m.ring.get(currencyID) == m.id
+}
+
+// Run отправляет heartbeat и обновляет кольцо до отмены контекста,
+// после чего удаляет реплику из списка участников
+func (m *ShardMembership) Run(ctx context.Context) {
+	defer m.leave()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.heartbeat(ctx); err != nil {
+			m.log.Warnf("Shard membership: heartbeat failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Обновляем свой heartbeat и перечитываем живых участников
+func (m *ShardMembership) heartbeat(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx,
+		`INSERT INTO worker_members (id, heartbeat_at) VALUES ($1, NOW())
+		 ON CONFLICT (id) DO UPDATE SET heartbeat_at = NOW()`,
+		m.id,
+	)
+	if err != nil {
+		return err
+	}
+
+	ttl := m.ttl.Seconds()
+	// Чистим давно умершие реплики, чтобы таблица не росла
+	if _, err := m.db.ExecContext(ctx,
+		"DELETE FROM worker_members WHERE heartbeat_at < NOW() - make_interval(secs => $1)",
+		ttl*10,
+	); err != nil {
+		return err
+	}
+
+	rows, err := m.db.QueryContext(ctx,
+		"SELECT id FROM worker_members WHERE heartbeat_at >= NOW() - make_interval(secs => $1) ORDER BY id",
+		ttl,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var members []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		members = append(members, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSeen = time.Now()
+	if !slices.Equal(members, m.members) {
+		m.members = members
+		m.ring = newHashRing(members)
+		m.log.Infof("Shard membership: rebalanced across %d replicas: %v", len(members), members)
+	}
+	return nil
+}
+
+// Удаляем себя из участников, чтобы остальные забрали шард без ожидания TTL
+func (m *ShardMembership) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := m.db.ExecContext(ctx, "DELETE FROM worker_members WHERE id = $1", m.id); err != nil {
+		m.log.Warnf("Shard membership: failed to leave: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package worker
+
+import "context"
+
+// Ownership решает, какие валюты обновляет текущая реплика.
+// Если у пула не задан Ownership, реплика обновляет все валюты.
+type Ownership interface {
+	// Owns сообщает, должна ли реплика запрашивать цену валюты
+	Owns(currencyID string) bool
+	// Run поддерживает владение (блокировки, heartbeat) до отмены контекста
+	Run(ctx context.Context)
+}
+
+// SetOwnership задает способ распределения валют между репликами.
+// Должен вызываться до Start.
+func (wp *WorkerPool) SetOwnership(ownership Ownership) {
+	wp.ownership = ownership
+}
+
+// owns проверяет, обновляет ли реплика валюту
+func (wp *WorkerPool) owns(currencyID string) bool {
+	return wp.ownership == nil || wp.ownership.Owns(currencyID)
+}
@@ -0,0 +1,68 @@
+package worker
+
+import "time"
+
+// Reconcile приводит набор отслеживаемых валют в соответствие с БД.
+// БД - единственный источник истины: валюты, которых нет в таблице, перестают
+// отслеживаться, новые - ставятся в очередь.
+func (wp *WorkerPool) Reconcile() error {
+	currencyList, err := wp.db.Currency().GetCurrencyList(wp.ctx)
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]struct{}, len(currencyList))
+	for _, id := range currencyList {
+		wanted[id] = struct{}{}
+	}
+
+	wp.mu.Lock()
+	added, removed := 0, 0
+	for id := range wanted {
+		if wp.addCurrency(id) {
+			added++
+		}
+	}
+	for id := range wp.currencies {
+		if _, ok := wanted[id]; !ok {
+			wp.removeCurrency(id)
+			removed++
+		}
+	}
+	wp.mu.Unlock()
+
+	if added > 0 || removed > 0 {
+		wp.notify()
+		wp.log.Infof("Reconciled currencies with database: %d added, %d removed", added, removed)
+	}
+	return nil
+}
+
+// Refresh просит пул сверить валюты с БД как можно скорее, не дожидаясь тика.
+// Несколько вызовов подряд схлопываются в одну сверку.
+func (wp *WorkerPool) Refresh() {
+	select {
+	case wp.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// Цикл периодической сверки с БД
+func (wp *WorkerPool) reconcileLoop() {
+	defer wp.wg.Done()
+
+	ticker := time.NewTicker(wp.reconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wp.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wp.refresh:
+		}
+		if err := wp.Reconcile(); err != nil {
+			wp.log.Errorf("Failed to reconcile currencies with DB: %v", err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// Число виртуальных узлов на реплику: сглаживает распределение валют
+const ringVirtualNodes = 128
+
+// hashRing - кольцо консистентного хеширования поверх живых реплик.
+// При входе или выходе реплики переезжает только ~1/N валют.
+type hashRing struct {
+	hashes  []uint64          // Отсортированные хеши виртуальных узлов
+	members map[uint64]string // Хеш виртуального узла -> id реплики
+}
+
+func newHashRing(members []string) *hashRing {
+	ring := &hashRing{
+		hashes:  make([]uint64, 0, len(members)*ringVirtualNodes),
+		members: make(map[uint64]string, len(members)*ringVirtualNodes),
+	}
+	for _, member := range members {
+		for i := 0; i < ringVirtualNodes; i++ {
+			h := hashKey(member + "#" + strconv.Itoa(i))
+			ring.hashes = append(ring.hashes, h)
+			ring.members[h] = member
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+	return ring
+}
+
+// get возвращает реплику, владеющую ключом, или "" для пустого кольца
+func (r *hashRing) get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.members[r.hashes[i]]
+}
+
+// hashKey хеширует ключ для кольца. У FNV-1a последние байты ключа почти не влияют
+// на старшие биты, и ключи вида coin-1, coin-2 ложатся на кольцо кучно.
+// Финализатор из MurmurHash3 перемешивает все биты.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
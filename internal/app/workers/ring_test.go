@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"strconv"
+	"testing"
+)
+
+func ringKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "coin-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func TestHashRingAddMember(t *testing.T) {
+	keys := ringKeys(1000)
+	before := newHashRing([]string{"a", "b", "c"})
+	after := newHashRing([]string{"a", "b", "c", "d"})
+
+	moved := 0
+	for _, key := range keys {
+		from, to := before.get(key), after.get(key)
+		if from == to {
+			continue
+		}
+		// Ключи переезжают только на новую реплику
+		if to != "d" {
+			t.Fatalf("key %s moved from %s to %s, not to the new member", key, from, to)
+		}
+		moved++
+	}
+	// Новой реплике достается около четверти ключей
+	if moved < 150 || moved > 350 {
+		t.Fatalf("moved %d of %d keys, want about a quarter", moved, len(keys))
+	}
+}
+
+func TestHashRingRemoveMember(t *testing.T) {
+	keys := ringKeys(1000)
+	before := newHashRing([]string{"a", "b", "c", "d"})
+	after := newHashRing([]string{"a", "b", "d"})
+
+	moved := 0
+	for _, key := range keys {
+		from, to := before.get(key), after.get(key)
+		if from == "c" {
+			if to == "c" {
+				t.Fatalf("key %s still owned by the removed member", key)
+			}
+			moved++
+			continue
+		}
+		// Ключи остальных реплик остаются на месте
+		if from != to {
+			t.Fatalf("key %s moved from %s to %s, but %s is still in the ring", key, from, to, from)
+		}
+	}
+	if moved == 0 {
+		t.Fatalf("removed member owned no keys")
+	}
+}
+
+func TestHashRingEmpty(t *testing.T) {
+	if owner := newHashRing(nil).get("bitcoin"); owner != "" {
+		t.Fatalf("empty ring owner = %q, want empty", owner)
+	}
+}
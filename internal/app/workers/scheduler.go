@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"container/heap"
+	"time"
+)
+
+// scheduledTask описывает валюту в очереди планировщика
+type scheduledTask struct {
+	currencyID string
+	due        time.Time // Время следующего запуска
+	index      int       // Позиция в куче, нужна для heap.Fix/heap.Remove
+}
+
+// taskQueue - min-heap задач, упорядоченная по времени следующего запуска
+type taskQueue []*scheduledTask
+
+func (q taskQueue) Len() int { return len(q) }
+
+func (q taskQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+
+func (q taskQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *taskQueue) Push(x any) {
+	task := x.(*scheduledTask)
+	task.index = len(*q)
+	*q = append(*q, task)
+}
+
+func (q *taskQueue) Pop() any {
+	old := *q
+	n := len(old)
+	task := old[n-1]
+	old[n-1] = nil
+	task.index = -1
+	*q = old[:n-1]
+	return task
+}
+
+// peek возвращает ближайшую задачу, не извлекая ее из очереди
+func (q taskQueue) peek() *scheduledTask {
+	if len(q) == 0 {
+		return nil
+	}
+	return q[0]
+}
+
+// schedule добавляет задачу в очередь
+func (q *taskQueue) schedule(task *scheduledTask) {
+	heap.Push(q, task)
+}
+
+// reschedule переносит задачу, уже находящуюся в очереди, на новое время
+func (q *taskQueue) reschedule(task *scheduledTask, due time.Time) {
+	task.due = due
+	heap.Fix(q, task.index)
+}
+
+// remove удаляет задачу из очереди
+func (q *taskQueue) remove(task *scheduledTask) {
+	if task.index >= 0 {
+		heap.Remove(q, task.index)
+	}
+}
@@ -2,91 +2,131 @@ package worker
 
 import (
 	"context"
+	"cryptoObserver/internal/app/anomaly"
 	coingecko "cryptoObserver/internal/app/coingeko"
+	"cryptoObserver/internal/app/model"
 	"cryptoObserver/internal/app/store/sqlstore"
 	"github.com/sirupsen/logrus"
 	"sync"
 	"time"
 )
 
+// Размер очереди задач между планировщиком и воркерами
+const taskQueueSize = 100
+
 type WorkerPool struct {
-	client      coingecko.CryptoInterface
-	db          sqlstore.StoreInterface
-	mu          sync.RWMutex
-	currencies  map[string]struct{}
-	workers     int
-	interval    time.Duration
-	ctx         context.Context
-	cancel      context.CancelFunc
-	wg          sync.WaitGroup
-	log         *logrus.Logger
-	taskChan    chan string     // Канал для распределения задач
-	activeTasks map[string]bool // Трекер активных задач
-	taskMu      sync.Mutex      // Защита activeTasks
+	source            Source // Провайдер цен, если не включены консенсус или переключение
+	db                sqlstore.StoreInterface
+	mu                sync.Mutex                // Защита currencies, queue и inFlight
+	currencies        map[string]*scheduledTask // Отслеживаемые валюты
+	queue             taskQueue                 // Очередь задач по времени следующего запуска
+	inFlight          map[string]struct{}       // Валюты, которые сейчас обрабатываются
+	workers           int
+	interval          time.Duration
+	reconcileInterval time.Duration // Период сверки списка валют с БД
+	ctx               context.Context
+	cancel            context.CancelFunc
+	wg                sync.WaitGroup
+	log               *logrus.Logger
+	taskChan          chan string        // Канал для распределения задач
+	wake              chan struct{}      // Сигнал планировщику пересчитать очередь
+	refresh           chan struct{}      // Запрос на внеочередную сверку с БД
+	ownership         Ownership          // Распределение валют между репликами
+	validator         *anomaly.Validator // Проверка входящих цен, nil - выключена
+	consensus         *consensus         // Опрос нескольких провайдеров, nil - выключен
+	failover          *failover          // Переключение на резервных провайдеров, nil - выключено
+	currencyCache     CurrencyCache      // Кеш хранилища, сбрасываемый при изменении валют, nil - нет
 }
 
 func NewWorkerPool(
 	ctx context.Context,
-	client coingecko.CryptoInterface,
+	source Source,
 	db sqlstore.StoreInterface,
 	workers int,
 	interval time.Duration,
+	reconcileInterval time.Duration,
 	log *logrus.Logger,
 ) *WorkerPool {
 	poolCtx, cancel := context.WithCancel(ctx)
 
 	return &WorkerPool{
-		client:      client,
-		db:          db,
-		currencies:  make(map[string]struct{}),
-		workers:     workers,
-		interval:    interval,
-		ctx:         poolCtx,
-		cancel:      cancel,
-		log:         log,
-		taskChan:    make(chan string, 100), // Буферизованный канал
-		activeTasks: make(map[string]bool),
+		source:            source,
+		db:                db,
+		currencies:        make(map[string]*scheduledTask),
+		inFlight:          make(map[string]struct{}),
+		workers:           workers,
+		interval:          interval,
+		reconcileInterval: reconcileInterval,
+		ctx:               poolCtx,
+		cancel:            cancel,
+		log:               log,
+		taskChan:          make(chan string, taskQueueSize), // Буферизованный канал
+		wake:              make(chan struct{}, 1),
+		refresh:           make(chan struct{}, 1),
 	}
 }
 
-// Добавляем валюту в список отслеживания
-func (wp *WorkerPool) AddCurrency(currencyID string) {
-	wp.mu.Lock()
-	defer wp.mu.Unlock()
-
-	if _, exists := wp.currencies[currencyID]; !exists {
-		wp.currencies[currencyID] = struct{}{}
-		wp.log.Infof("Currency added: %s", currencyID)
+// Добавляем валюту в список отслеживания. Вызывается под wp.mu.
+// Возвращает true, если валюта не отслеживалась ранее.
+func (wp *WorkerPool) addCurrency(currencyID string) bool {
+	if _, exists := wp.currencies[currencyID]; exists {
+		return false
 	}
+	task := &scheduledTask{currencyID: currencyID, due: time.Now()}
+	wp.currencies[currencyID] = task
+	wp.queue.schedule(task)
+	wp.log.Infof("Currency added: %s", currencyID)
+	return true
 }
 
-// Удаляем валюту из списка отслеживания
-func (wp *WorkerPool) RemoveCurrency(currencyID string) {
-	wp.mu.Lock()
-	defer wp.mu.Unlock()
+// Удаляем валюту из списка отслеживания. Вызывается под wp.mu.
+func (wp *WorkerPool) removeCurrency(currencyID string) {
+	if task, exists := wp.currencies[currencyID]; exists {
+		wp.queue.remove(task)
+		delete(wp.currencies, currencyID)
+		if wp.validator != nil {
+			wp.validator.Forget(currencyID)
+		}
+		if wp.failover != nil {
+			wp.failover.forget(currencyID)
+		}
+		wp.log.Infof("Currency removed: %s", currencyID)
+	}
+	// Кеш сбрасывается, даже если пул валюту не отслеживал: ее id мог попасть в кеш при записи
+	wp.forgetCached(currencyID)
+}
 
-	delete(wp.currencies, currencyID)
-	wp.log.Infof("Currency removed: %s", currencyID)
+// Сбрасываем кеш хранилища для валюты
+func (wp *WorkerPool) forgetCached(currencyID string) {
+	if wp.currencyCache != nil {
+		wp.currencyCache.ForgetCurrency(currencyID)
+	}
 }
 
 // Запускаем воркер-пул
 func (wp *WorkerPool) Start() {
 	// Загружаем список валют из БД
-	currencyList, err := wp.db.Currency().GetCurrencyList()
-	if err != nil {
+	if err := wp.Reconcile(); err != nil {
 		wp.log.Errorf("Failed to get currency list from DB: %v", err)
-	} else if len(currencyList) > 0 {
-		wp.mu.Lock()
-		for _, id := range currencyList {
-			wp.currencies[id] = struct{}{}
-		}
-		wp.mu.Unlock()
-		wp.log.Infof("Loaded %d currencies from database", len(currencyList))
+	}
+
+	// Поддерживаем владение валютами между репликами
+	if wp.ownership != nil {
+		wp.wg.Add(1)
+		go func() {
+			defer wp.wg.Done()
+			wp.ownership.Run(wp.ctx)
+		}()
 	}
 
 	// Запускаем распределитель задач
+	wp.wg.Add(1)
 	go wp.taskDispatcher()
 
+	// Запускаем периодическую сверку с БД
+	wp.wg.Add(1)
+	go wp.reconcileLoop()
+
 	// Запускаем воркеров
 	for i := 0; i < wp.workers; i++ {
 		wp.wg.Add(1)
@@ -94,37 +134,90 @@ func (wp *WorkerPool) Start() {
 	}
 }
 
+// notify будит планировщик, не блокируясь, если сигнал уже отправлен
+func (wp *WorkerPool) notify() {
+	select {
+	case wp.wake <- struct{}{}:
+	default:
+	}
+}
+
 // Распределитель задач
 func (wp *WorkerPool) taskDispatcher() {
-	ticker := time.NewTicker(wp.interval)
-	defer ticker.Stop()
+	defer wp.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-wp.ctx.Done():
 			return
-		case <-ticker.C:
-			wp.dispatchTasks()
+		case <-timer.C:
+		case <-wp.wake:
 		}
+		timer.Reset(wp.dispatchTasks())
 	}
 }
 
-// Распределяем задачи по воркерам
-func (wp *WorkerPool) dispatchTasks() {
-	wp.mu.RLock()
-	defer wp.mu.RUnlock()
+// Распределяем наступившие задачи по воркерам.
+// Отправка в taskChan никогда не блокируется: если очередь заполнена, оставшиеся
+// задачи ждут, пока воркер не освободится и не разбудит планировщик.
+// Возвращает время до следующей задачи.
+func (wp *WorkerPool) dispatchTasks() time.Duration {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	now := time.Now()
+	skipped := 0
+	defer func() {
+		if skipped > 0 {
+			wp.log.Warnf("Skipped %d currencies: previous update is still in progress", skipped)
+		}
+	}()
+
+	for {
+		task := wp.queue.peek()
+		if task == nil {
+			return wp.interval
+		}
+		if task.due.After(now) {
+			return task.due.Sub(now)
+		}
+
+		// Валюту обновляет другая реплика
+		if !wp.owns(task.currencyID) {
+			wp.queue.reschedule(task, nextDue(task.due, now, wp.interval))
+			continue
+		}
 
-	wp.taskMu.Lock()
-	defer wp.taskMu.Unlock()
+		// Предыдущее обновление еще не завершилось - пропускаем тик
+		if _, busy := wp.inFlight[task.currencyID]; busy {
+			wp.queue.reschedule(task, now.Add(wp.interval))
+			skipped++
+			continue
+		}
 
-	for currencyID := range wp.currencies {
-		if !wp.activeTasks[currencyID] {
-			wp.activeTasks[currencyID] = true
-			wp.taskChan <- currencyID
+		select {
+		case wp.taskChan <- task.currencyID:
+			wp.inFlight[task.currencyID] = struct{}{}
+			wp.queue.reschedule(task, nextDue(task.due, now, wp.interval))
+		default:
+			// Очередь заполнена, ждем сигнала от воркера
+			return wp.interval
 		}
 	}
 }
 
+// nextDue вычисляет следующий запуск, схлопывая пропущенные тики в один
+func nextDue(due, now time.Time, interval time.Duration) time.Time {
+	next := due.Add(interval)
+	if next.Before(now) {
+		return now.Add(interval)
+	}
+	return next
+}
+
 // Воркер
 func (wp *WorkerPool) runWorker() {
 	defer wp.wg.Done()
@@ -142,19 +235,57 @@ func (wp *WorkerPool) runWorker() {
 // Обработка одной валюты
 func (wp *WorkerPool) processCurrency(currencyID string) {
 	defer func() {
-		wp.taskMu.Lock()
-		delete(wp.activeTasks, currencyID)
-		wp.taskMu.Unlock()
+		wp.mu.Lock()
+		delete(wp.inFlight, currencyID)
+		wp.mu.Unlock()
+		wp.notify()
 	}()
 
-	price, err := wp.client.GetCryptoPrice(wp.ctx, currencyID)
+	// Валюта могла быть удалена, пока задача стояла в очереди
+	wp.mu.Lock()
+	_, tracked := wp.currencies[currencyID]
+	wp.mu.Unlock()
+	if !tracked {
+		return
+	}
+
+	timestamp := time.Now().Unix()
+	price, source, err := wp.fetchPrice(currencyID, timestamp)
 	if err != nil {
 		wp.log.Errorf("Failed to fetch %s: %v", currencyID, err)
 		return
 	}
 
-	if err := wp.db.Currency().UpdatePrice(currencyID, price.CurrentPrice, time.Now().Unix()); err != nil {
+	sample := anomaly.Sample{Price: price.CurrentPrice, Timestamp: timestamp, SourceUpdated: price.LastUpdated}
+	if !wp.validate(currencyID, sample) {
+		return
+	}
+	if err := wp.db.Currency().UpdatePrice(wp.ctx, currencyID, price.CurrentPrice, timestamp, source); err != nil {
 		wp.log.Errorf("Failed to save %s: %v", currencyID, err)
+		return
+	}
+	if wp.validator != nil {
+		wp.validator.Accept(currencyID, sample)
+	}
+
+	if err := wp.db.Market().SaveSnapshot(wp.ctx, currencyID, price.Snapshot(timestamp)); err != nil {
+		wp.log.Errorf("Failed to save market snapshot %s: %v", currencyID, err)
+	}
+}
+
+// fetchPrice запрашивает цену валюты у провайдера, у всех провайдеров сразу в режиме
+// консенсуса или у доступного провайдера в режиме переключения.
+// Возвращает цену и ее источник для записи в историю.
+func (wp *WorkerPool) fetchPrice(currencyID string, timestamp int64) (*coingecko.CryptoPriceResponse, string, error) {
+	switch {
+	case wp.consensus != nil:
+		price, err := wp.fetchConsensus(currencyID, timestamp)
+		return price, model.SourceConsensus, err
+	case wp.failover != nil:
+		return wp.fetchFailover(currencyID)
+	default:
+		price, err := wp.source.Client.GetCryptoPrice(wp.ctx, currencyID)
+		return price, wp.source.Name, err
 	}
 }
 
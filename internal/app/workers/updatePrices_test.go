@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"context"
+	coingecko "cryptoObserver/internal/app/coingeko"
+	"cryptoObserver/internal/app/model"
+	"cryptoObserver/internal/app/store/memstore"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClient отвечает фиксированной ценой с задержкой и считает запросы по валютам
+type fakeClient struct {
+	delay time.Duration
+	mu    sync.Mutex
+	calls map[string]int
+	total atomic.Int64
+}
+
+func newFakeClient(delay time.Duration) *fakeClient {
+	return &fakeClient{delay: delay, calls: make(map[string]int)}
+}
+
+func (c *fakeClient) GetCryptoPrice(ctx context.Context, id string) (*coingecko.CryptoPriceResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(c.delay):
+	}
+	c.mu.Lock()
+	c.calls[id]++
+	c.mu.Unlock()
+	c.total.Add(1)
+	return &coingecko.CryptoPriceResponse{ID: id, CurrentPrice: model.NewDecimal(100, 0)}, nil
+}
+
+// fetched возвращает число валют, запрошенных хотя бы раз
+func (c *fakeClient) fetched() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
+
+func newTestLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+// TestWorkerPoolLoad отслеживает несколько тысяч валют при очереди задач на 100 мест:
+// каждая валюта должна быть запрошена, добавление и удаление валют не должно ждать
+// планировщик, а остановка пула не должна зависать.
+func TestWorkerPoolLoad(t *testing.T) {
+	const (
+		currencies = 5000
+		workers    = 20
+	)
+	ctx := context.Background()
+	store := memstore.New()
+	for i := 0; i < currencies; i++ {
+		if err := store.Currency().AddCurrency(ctx, fmt.Sprintf("coin-%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := newFakeClient(200 * time.Microsecond)
+	pool := NewWorkerPool(ctx, Source{Name: "fake", Client: client}, store, workers, time.Second, time.Minute, newTestLogger())
+	pool.Start()
+
+	// Параллельно с обработкой добавляем и удаляем валюты, как это делает LISTEN/NOTIFY
+	var maxLatency atomic.Int64
+	stopChurn := make(chan struct{})
+	churnDone := make(chan struct{})
+	go func() {
+		defer close(churnDone)
+		for i := 0; ; i++ {
+			select {
+			case <-stopChurn:
+				return
+			default:
+			}
+			op := "add"
+			if i%2 == 1 {
+				op = "remove"
+			}
+			started := time.Now()
+			pool.handleCurrencyEvent(fmt.Sprintf(`{"op":%q,"symbol":"churn-%d"}`, op, i/2))
+			if latency := int64(time.Since(started)); latency > maxLatency.Load() {
+				maxLatency.Store(latency)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	deadline := time.Now().Add(20 * time.Second)
+	for client.fetched() < currencies && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(stopChurn)
+	<-churnDone
+
+	stopped := make(chan struct{})
+	go func() {
+		pool.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker pool did not stop: deadlock")
+	}
+
+	// Валюты churn-* не входят в проверку: их удаляют раньше, чем до них доходит очередь
+	client.mu.Lock()
+	missing := 0
+	for i := 0; i < currencies; i++ {
+		if client.calls[fmt.Sprintf("coin-%d", i)] == 0 {
+			missing++
+		}
+	}
+	client.mu.Unlock()
+	if missing > 0 {
+		t.Fatalf("%d of %d currencies were never dispatched", missing, currencies)
+	}
+	if latency := time.Duration(maxLatency.Load()); latency > 100*time.Millisecond {
+		t.Errorf("currency add/remove waited %v for the pool lock", latency)
+	}
+	t.Logf("%d fetches, max add/remove latency %v", client.total.Load(), time.Duration(maxLatency.Load()))
+}
+
+// TestDispatchSkipsBusyCurrency проверяет, что валюта, еще обрабатываемая воркером,
+// не ставится в очередь второй раз, а ее тик переносится на следующий период
+func TestDispatchSkipsBusyCurrency(t *testing.T) {
+	pool := NewWorkerPool(context.Background(), Source{Name: "fake", Client: newFakeClient(0)}, memstore.New(), 1, time.Minute, time.Minute, newTestLogger())
+	pool.mu.Lock()
+	pool.addCurrency("bitcoin")
+	pool.inFlight["bitcoin"] = struct{}{}
+	pool.mu.Unlock()
+
+	pool.dispatchTasks()
+	if len(pool.taskChan) != 0 {
+		t.Fatalf("busy currency was dispatched again")
+	}
+	if due := pool.currencies["bitcoin"].due; time.Until(due) < 59*time.Second {
+		t.Fatalf("busy currency rescheduled to %v, want about one interval from now", due)
+	}
+}
+
+func TestNextDueCoalescesMissedTicks(t *testing.T) {
+	now := time.Unix(1000, 0)
+	interval := 10 * time.Second
+	if got := nextDue(now.Add(-5*time.Second), now, interval); !got.Equal(now.Add(5 * time.Second)) {
+		t.Errorf("on time: got %v", got)
+	}
+	// Пропущено несколько тиков - следующий запуск через период от текущего момента
+	if got := nextDue(now.Add(-time.Minute), now, interval); !got.Equal(now.Add(interval)) {
+		t.Errorf("missed ticks: got %v", got)
+	}
+}
+
+// fakeCurrencyCache запоминает сброшенные валюты
+type fakeCurrencyCache struct {
+	forgotten []string
+}
+
+func (c *fakeCurrencyCache) ForgetCurrency(symbol string) {
+	c.forgotten = append(c.forgotten, symbol)
+}
+
+// TestCurrencyEventsResetStoreCache проверяет, что уведомления о валютах, в том
+// числе от других реплик, сбрасывают кеш id хранилища
+func TestCurrencyEventsResetStoreCache(t *testing.T) {
+	pool := NewWorkerPool(context.Background(), Source{Name: "fake", Client: newFakeClient(0)}, memstore.New(), 1, time.Minute, time.Minute, newTestLogger())
+	cache := &fakeCurrencyCache{}
+	pool.SetCurrencyCache(cache)
+
+	pool.handleCurrencyEvent(`{"op":"add","symbol":"bitcoin"}`)
+	pool.handleCurrencyEvent(`{"op":"remove","symbol":"bitcoin"}`)
+	// Валюту, которую пул не отслеживал, тоже нужно сбросить
+	pool.handleCurrencyEvent(`{"op":"remove","symbol":"ethereum"}`)
+
+	if got := fmt.Sprint(cache.forgotten); got != "[bitcoin bitcoin ethereum]" {
+		t.Fatalf("forgotten = %s, want [bitcoin bitcoin ethereum]", got)
+	}
+}
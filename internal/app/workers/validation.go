@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"cryptoObserver/internal/app/anomaly"
+	"cryptoObserver/internal/app/model"
+	"time"
+)
+
+// validatorSeedIntervals - за сколько периодов опроса поднимается история
+// валидатора из БД при первой проверке валюты
+const validatorSeedIntervals = 20
+
+// SetValidator включает проверку входящих цен на аномалии.
+// Должен вызываться до Start.
+func (wp *WorkerPool) SetValidator(validator *anomaly.Validator) {
+	wp.validator = validator
+}
+
+// AcceptQuarantined сообщает валидатору о цене, принятой из карантина вручную.
+// Принятая цена считается новым уровнем: история валюты начинается с нее,
+// иначе следующие точки на том же уровне снова уходили бы в карантин как скачок.
+func (wp *WorkerPool) AcceptQuarantined(currencyID string, price model.Decimal) {
+	if wp.validator == nil {
+		return
+	}
+	wp.validator.Seed(currencyID, []model.Decimal{price})
+}
+
+// validate проверяет точку перед записью. Подозрительная точка уходит в карантин,
+// тогда validate возвращает false и точку записывать не нужно.
+func (wp *WorkerPool) validate(currencyID string, sample anomaly.Sample) bool {
+	if wp.validator == nil {
+		return true
+	}
+	if !wp.validator.Known(currencyID) {
+		wp.seedValidator(currencyID)
+	}
+
+	reason, detail := wp.validator.Check(currencyID, sample)
+	if reason == "" {
+		return true
+	}
+	wp.log.Warnf("Price of %s quarantined (%s): %s", currencyID, reason, detail)
+	_, err := wp.db.Quarantine().AddQuarantined(wp.ctx, model.QuarantinedPrice{
+		CoinID:    currencyID,
+		Price:     sample.Price,
+		Timestamp: sample.Timestamp,
+		Reason:    reason,
+		Detail:    detail,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		wp.log.Errorf("Failed to quarantine price of %s: %v", currencyID, err)
+	}
+	return false
+}
+
+// seedValidator поднимает историю валидатора из сохраненных цен,
+// чтобы после перезапуска скачки проверялись с первой точки
+func (wp *WorkerPool) seedValidator(currencyID string) {
+	to := time.Now().Unix()
+	from := to - int64(wp.interval/time.Second)*validatorSeedIntervals
+	series, err := wp.db.Currency().GetPriceSeries(wp.ctx, currencyID, from, to)
+	if err != nil {
+		// Без истории проверка скачков начнется после нескольких принятых точек
+		wp.log.Errorf("Failed to load price history of %s for validation: %v", currencyID, err)
+		return
+	}
+	prices := make([]model.Decimal, len(series))
+	for i, point := range series {
+		prices[i] = point.Price
+	}
+	wp.validator.Seed(currencyID, prices)
+}